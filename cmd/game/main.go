@@ -24,10 +24,12 @@ func main() {
 	ebiten.SetWindowTitle("BankWave: Neon Networth")
 
 	game := &internal.Game{
-		Width:  gameWidth,
-		Height: gameHeight,
-		ACtx:   audio.NewContext(internal.SampleRate),
+		Width:    gameWidth,
+		Height:   gameHeight,
+		ACtx:     audio.NewContext(internal.SampleRate),
+		Settings: internal.LoadSettings(),
 	}
+	game.Settings.Apply()
 
 	game.CurrScene = internal.NewLogoScene(game)
 	if err := ebiten.RunGame(game); err != nil {
@@ -0,0 +1,32 @@
+// Command yarnc compiles every .yarn file in a directory into a program and string tables which yarn.LoadFiles can
+// read, so dialogue can be changed with nothing but the Go toolchain.
+//
+//	go run ./cmd/yarnc -n game -o internal/gamedata/yarn/bin internal/gamedata/yarn
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/Frabjous-Studios/bankwave/internal/yarnc"
+	"os"
+)
+
+func main() {
+	name := flag.String("n", "game", "name of the compiled program")
+	out := flag.String("o", ".", "directory to write the program and string tables to")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "usage: yarnc [-n name] [-o dir] <directory of .yarn files>")
+		os.Exit(2)
+	}
+	result, err := yarnc.CompileDir(flag.Arg(0), *name)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if err := result.WriteFiles(*out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Printf("compiled %d nodes and %d lines into %s\n", len(result.Program.Nodes), len(result.Lines), *out)
+}
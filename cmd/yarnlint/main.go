@@ -0,0 +1,35 @@
+// Command yarnlint checks compiled Yarn against the game: the day schedule, node headers, commands, sounds and
+// portraits. Each mistake is printed with the file and line it was found near, and the exit status is non-zero if
+// there are any.
+//
+//	go run ./cmd/yarnlint internal/gamedata/yarn/bin/game.yarnc
+package main
+
+import (
+	"flag"
+	"fmt"
+	"github.com/DrJosh9000/yarn"
+	"github.com/Frabjous-Studios/bankwave/internal"
+	"os"
+)
+
+func main() {
+	flag.Parse()
+	path := "internal/gamedata/yarn/bin/game.yarnc"
+	if flag.NArg() > 0 {
+		path = flag.Arg(0)
+	}
+	prog, st, err := yarn.LoadFiles(path, "en-US")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	errs := internal.Lint(prog, st)
+	for _, e := range errs {
+		fmt.Println(e.Error())
+	}
+	if len(errs) > 0 {
+		fmt.Fprintf(os.Stderr, "%d problems found\n", len(errs))
+		os.Exit(1)
+	}
+}
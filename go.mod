@@ -17,6 +17,8 @@ require (
 require (
 	github.com/solarlune/resound v0.0.0-20230424050050-0e99704df6fa
 	golang.org/x/exp v0.0.0-20230425010034-47ecfdc1ba53
+	golang.org/x/text v0.9.0
+	google.golang.org/protobuf v1.30.0
 )
 
 require (
@@ -36,7 +38,5 @@ require (
 	golang.org/x/mobile v0.0.0-20230427221453-e8d11dd0ba41 // indirect
 	golang.org/x/sync v0.1.0 // indirect
 	golang.org/x/sys v0.7.0 // indirect
-	golang.org/x/text v0.9.0 // indirect
-	google.golang.org/protobuf v1.30.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
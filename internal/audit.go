@@ -0,0 +1,58 @@
+package internal
+
+import "fmt"
+
+// DisposalAudit records everything the teller shredded or threw down the trash chute today.
+type DisposalAudit struct {
+	CashShredded int // CashShredded is the value of genuine scrip and tokens shredded, in cents.
+	CashTrashed  int // CashTrashed is the value of genuine scrip and tokens thrown away, in cents.
+	ValidDocs    int // ValidDocs counts slips and checks with nothing wrong with them which were destroyed.
+	Evidence     int // Evidence counts bad slips and bad checks which were destroyed.
+	IDs          int // IDs counts customer IDs which were destroyed.
+	Trash        int // Trash counts actual trash; the only thing that's supposed to be thrown away.
+}
+
+// Record records the destruction of the provided sprite, either by shredding or by throwing it in the trash.
+func (a *DisposalAudit) Record(s Sprite, shredded bool) {
+	cash := &a.CashTrashed
+	if shredded {
+		cash = &a.CashShredded
+	}
+	switch s := s.(type) {
+	case *Money:
+		*cash += s.Value
+	case *Stack:
+		*cash += s.Count * s.Value * 100
+	case *DepositSlip:
+		if s.Error == SlipErrorNone && (s.ForDeposit || s.ForWithdrawal) {
+			a.ValidDocs++
+		} else {
+			a.Evidence++
+		}
+	case *Check:
+		if s.Valid && s.Signed && routingValid(s.Routing) {
+			a.ValidDocs++
+		} else {
+			a.Evidence++
+		}
+	case *PhotoID:
+		a.IDs++
+	case *Trash:
+		a.Trash++
+	}
+}
+
+// CashDestroyed is the total value of all genuine cash destroyed, in cents.
+func (a *DisposalAudit) CashDestroyed() int {
+	return a.CashShredded + a.CashTrashed
+}
+
+// Clean is true if nothing but trash was destroyed.
+func (a *DisposalAudit) Clean() bool {
+	return a.CashDestroyed() == 0 && a.ValidDocs == 0 && a.Evidence == 0 && a.IDs == 0
+}
+
+func (a *DisposalAudit) String() string {
+	return fmt.Sprintf("cash=%s docs=%d evidence=%d ids=%d trash=%d",
+		fmtCents(a.CashDestroyed()), a.ValidDocs, a.Evidence, a.IDs, a.Trash)
+}
@@ -0,0 +1,261 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/Frabjous-Studios/bankwave/internal/debug"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/tinne26/etxt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Backlog is the history of everything said at the window, kept so the player can look back at lines which have timed
+// out. Press H to open it.
+type Backlog struct {
+	scene *MainScene
+	txt   *etxt.Renderer
+
+	Open         bool
+	customerOnly bool // customerOnly shows just the current customer, rather than the whole day.
+	scroll       int  // scroll is the number of lines scrolled back from the most recent.
+	status       string
+
+	mut    sync.Mutex // mut guards visits, which dialogue is recorded into from its own goroutines.
+	visits []*BacklogVisit
+}
+
+// BacklogVisit is everything said while one customer was at the window.
+type BacklogVisit struct {
+	Day      int
+	Customer string
+	Node     string
+	Entries  []BacklogEntry
+}
+
+// BacklogEntry is a line of dialogue, or an option the player chose.
+type BacklogEntry struct {
+	Speaker string
+	Text    string
+	Chosen  bool // Chosen is set for options the player picked.
+}
+
+const backlogFontSize = 16
+const backlogWrapWidth = 76
+
+var backlogColor = h2c("ffffff")
+var backlogChosenColor = fontColorHighlight
+
+func NewBacklog(txt *etxt.Renderer, scene *MainScene) *Backlog {
+	return &Backlog{scene: scene, txt: txt}
+}
+
+// StartVisit starts recording what's said with a new customer at the window.
+func (b *Backlog) StartVisit(day int, customer, node string) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	b.visits = append(b.visits, &BacklogVisit{Day: day, Customer: customer, Node: node})
+	b.scroll = 0
+}
+
+// Say records a line of dialogue.
+func (b *Backlog) Say(speaker, text string) {
+	b.add(BacklogEntry{Speaker: speaker, Text: text})
+}
+
+// Choose records the option the player chose.
+func (b *Backlog) Choose(text string) {
+	b.add(BacklogEntry{Text: text, Chosen: true})
+}
+
+func (b *Backlog) add(e BacklogEntry) {
+	if strings.TrimSpace(e.Text) == "" {
+		return
+	}
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	if len(b.visits) == 0 {
+		b.visits = append(b.visits, &BacklogVisit{})
+	}
+	v := b.visits[len(b.visits)-1]
+	v.Entries = append(v.Entries, e)
+}
+
+// Lines lays out the visit as it's shown in the backlog and the transcript; each line is at most width characters.
+// Options the player chose are indented.
+func (v *BacklogVisit) Lines(width int) []string {
+	result := wrapText(T("backlog.visit", v.Customer, v.Node), width)
+	for _, e := range v.Entries {
+		switch {
+		case e.Chosen:
+			result = append(result, indent(wrapText("> "+e.Text, width-2), "  ")...)
+		case e.Speaker != "":
+			result = append(result, wrapText(e.Speaker+": "+e.Text, width)...)
+		default:
+			result = append(result, wrapText(e.Text, width)...)
+		}
+	}
+	return result
+}
+
+func indent(lines []string, prefix string) []string {
+	for i := range lines {
+		lines[i] = prefix + lines[i]
+	}
+	return lines
+}
+
+// WriteTo writes a plain-text transcript of every visit so far, day by day.
+func (b *Backlog) WriteTo(w io.Writer) (int64, error) {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	var sb strings.Builder
+	sb.WriteString(T("backlog.transcript", time.Now().Format("2006-01-02 15:04")) + "\n")
+	day := -1
+	for _, v := range b.visits {
+		if v.Day != day {
+			day = v.Day
+			sb.WriteString("\n" + T("backlog.day", day) + "\n")
+		}
+		sb.WriteString("\n" + strings.Join(v.Lines(backlogWrapWidth), "\n") + "\n")
+	}
+	n, err := io.WriteString(w, sb.String())
+	return int64(n), err
+}
+
+// Export saves a transcript to the transcripts directory beside the player's settings, and returns where it went.
+func (b *Backlog) Export() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "transcripts")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, time.Now().Format("transcript-20060102-150405.txt"))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	if _, err := b.WriteTo(f); err != nil {
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// shown lays out the lines which can be scrolled through: the current customer's, or the whole of the current day's.
+func (b *Backlog) shown() []string {
+	b.mut.Lock()
+	defer b.mut.Unlock()
+	if len(b.visits) == 0 {
+		return nil
+	}
+	last := b.visits[len(b.visits)-1]
+	var result []string
+	for _, v := range b.visits {
+		if v.Day != last.Day || (b.customerOnly && v != last) {
+			continue
+		}
+		if len(result) > 0 {
+			result = append(result, "")
+		}
+		result = append(result, v.Lines(backlogWrapWidth)...)
+	}
+	return result
+}
+
+func (b *Backlog) Update() {
+	if inpututil.IsKeyJustPressed(ebiten.KeyH) && !b.scene.CapturingText() {
+		b.Open = !b.Open
+		b.scroll, b.status = 0, ""
+		return
+	}
+	if !b.Open {
+		return
+	}
+	_, wheel := ebiten.Wheel()
+	switch {
+	case repeatingKeyPressed(ebiten.KeyArrowUp) || wheel > 0:
+		b.scroll++
+	case repeatingKeyPressed(ebiten.KeyArrowDown) || wheel < 0:
+		b.scroll--
+	case repeatingKeyPressed(ebiten.KeyPageUp):
+		b.scroll += backlogLinesShown
+	case repeatingKeyPressed(ebiten.KeyPageDown):
+		b.scroll -= backlogLinesShown
+	case inpututil.IsKeyJustPressed(ebiten.KeyTab):
+		b.customerOnly = !b.customerOnly
+		b.scroll = 0
+	case inpututil.IsKeyJustPressed(ebiten.KeyE):
+		path, err := b.Export()
+		if err != nil {
+			debug.Printf("error exporting transcript: %v", err)
+			b.status = T("backlog.export_failed")
+			break
+		}
+		debug.Println("transcript saved to", path)
+		b.status = T("backlog.exported", path)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		b.Open = false
+	}
+	b.scroll = clamp(b.scroll, 0, max(0, len(b.shown())-backlogLinesShown))
+}
+
+const backlogLineHeight = backlogFontSize + 2
+const backlogLinesShown = 22
+
+func (b *Backlog) DrawTo(screen *ebiten.Image) {
+	if !b.Open {
+		return
+	}
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(float64(screen.Bounds().Dx()), float64(screen.Bounds().Dy()))
+	opts.ColorScale.Scale(1, 1, 1, 0.85)
+	screen.DrawImage(b.scene.black, opts)
+
+	v, h := b.txt.GetAlign()
+	defer b.txt.SetAlign(v, h)
+	b.txt.SetTarget(screen)
+	b.txt.SetFont(Resources.GetFont(DialogFont))
+	b.txt.SetSizePx(backlogFontSize)
+	b.txt.SetAlign(etxt.Top, etxt.Left)
+
+	title := T("backlog.today")
+	if b.customerOnly {
+		title = T("backlog.customer")
+	}
+	b.txt.SetColor(fontColorHighlight)
+	b.txt.Draw(title, 10, 8)
+
+	lines := b.shown()
+	if len(lines) == 0 {
+		lines = []string{T("backlog.empty")}
+	}
+	end := len(lines) - b.scroll
+	start := max(0, end-backlogLinesShown)
+	y := 8 + 2*backlogLineHeight
+	for _, line := range lines[start:end] {
+		b.txt.SetColor(backlogColor)
+		if strings.HasPrefix(line, "  ") {
+			b.txt.SetColor(backlogChosenColor)
+		}
+		b.txt.Draw(line, 10, y)
+		y += backlogLineHeight
+	}
+
+	b.txt.SetColor(fontColorHighlight)
+	footer := T("backlog.keys")
+	if b.status != "" {
+		footer = b.status
+	}
+	b.txt.Draw(footer, 10, screen.Bounds().Dy()-8-backlogLineHeight)
+	if b.scroll > 0 {
+		b.txt.Draw(fmt.Sprintf("^ %d", b.scroll), screen.Bounds().Dx()-60, 8)
+	}
+}
@@ -0,0 +1,34 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestBacklog_WriteTo(t *testing.T) {
+	b := &Backlog{}
+	b.StartVisit(1, "Ada Lovelace", "RandomDeposit_Polite")
+	b.Say("", "I'd like to deposit 12.50.")
+	b.Choose("Sure thing.")
+	b.Say("Ada Lovelace", "Thanks!")
+	b.Say("", "") // blank lines aren't recorded.
+	b.StartVisit(2, "Mr. Manager", "Manager_Day2")
+
+	var sb strings.Builder
+	_, err := b.WriteTo(&sb)
+	assert.NoError(t, err)
+	_, transcript, _ := strings.Cut(sb.String(), "\n")
+	assert.EqualValues(t, `
+== DAY 1 ==
+
+-- Ada Lovelace (RandomDeposit_Polite) --
+I'd like to deposit 12.50.
+  > Sure thing.
+Ada Lovelace: Thanks!
+
+== DAY 2 ==
+
+-- Mr. Manager (Manager_Day2) --
+`, transcript)
+}
@@ -0,0 +1,40 @@
+package internal
+
+import "strconv"
+
+const (
+	VarAccountBalance = "$account_balance" // VarAccountBalance is the checking balance of the current customer's account, in dollars.
+	VarDay            = "$day"             // VarDay is the number of the current day, starting from 1.
+	VarTillValue      = "$till_value"      // VarTillValue is the cash in the till, in dollars.
+	VarImbalance      = "$imbalance"       // VarImbalance is how far the till is off from what the slips say, in dollars.
+	VarScore          = "$score"           // VarScore is the number of customers served, over every day so far.
+)
+
+// bindVars exposes the current state of the game to Yarn, before each node runs.
+func (m *MainScene) bindVars() {
+	m.Runner.SetVar(VarDay, float32(m.dayIdx+1))
+	m.Runner.SetVar(VarTillValue, float32(m.till.Value())/100)
+	m.Runner.SetVar(VarImbalance, float32(m.till.Imbalance())/100)
+	m.Runner.SetVar(VarScore, float32(m.served))
+	m.bindAccount()
+}
+
+// bindAccount exposes the balance of the current customer's account to Yarn; it's 0 for customers without one.
+func (m *MainScene) bindAccount() {
+	var balance int
+	if m.Customer != nil && m.Customer.DepositSlip != nil {
+		if acct, ok := m.Day.Accounts[strconv.Itoa(m.Customer.DepositSlip.AcctNum)]; ok {
+			balance = acct.Checking
+		}
+	}
+	m.Runner.SetVar(VarAccountBalance, float32(balance)/100)
+}
+
+// countServed counts the departing customer toward the score, unless they stormed off.
+func (m *MainScene) countServed() {
+	if m.Customer == nil || m.Customer.CustomerIntent == "" || m.Customer.Treatment() == TreatedStormedOff {
+		return
+	}
+	m.served++
+	m.Runner.SetVar(VarScore, float32(m.served))
+}
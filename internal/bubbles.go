@@ -8,6 +8,9 @@ import (
 	"github.com/tinne26/etxt/emask"
 	"golang.org/x/image/math/fixed"
 	"image"
+	"image/color"
+	"math"
+	"math/rand"
 	"time"
 	"unicode/utf8"
 )
@@ -21,6 +24,9 @@ var (
 	fontColorHighlight = h2c("ffff00")
 )
 
+// fastForwardDelay is how long a line which has been seen before is shown while fast-forwarding.
+const fastForwardDelay = 150 * time.Millisecond
+
 // bubbleDelay is the min amount of time to show a bubble before moving on to the next dialogue option.
 const bubbleDelay = 5 * time.Second
 
@@ -65,16 +71,41 @@ func NewBubbles(m *MainScene) *Bubbles {
 }
 
 func (b *Bubbles) SetLine(str string) {
-	b.stack = []*Line{NewLine(str)}
+	b.Present(str, Presentation{})
+}
+
+// Present shows a line of dialogue the way it's directed to be shown.
+func (b *Bubbles) Present(str string, p Presentation) {
+	if p.Speaker != "" {
+		str = p.Speaker + ": " + str
+		p.Markup = shiftMarkup(p.Markup, utf8.RuneCountInString(p.Speaker+": "))
+	}
+	line := NewLine(str)
+	line.pres = p
+	b.stack = []*Line{line}
 	b.startTime = time.Now()
 	b.advanced = false
 }
 
+// delay is how long the current line is shown before moving on.
+func (b *Bubbles) delay() time.Duration {
+	if len(b.stack) > 0 && b.stack[0].pres.Auto > 0 {
+		return b.stack[0].pres.Auto
+	}
+	return bubbleDelay
+}
+
 var lastLog = time.Time{}
 
 func (b *Bubbles) Update() {
-	if !b.startTime.IsZero() && (b.IsDone() || time.Now().Sub(b.startTime) > bubbleDelay) {
-		if time.Now().Sub(b.startTime) > bubbleDelay && lastLog != b.startTime {
+	b.blip()
+	if !b.startTime.IsZero() && b.stack[0].pres.Seen && b.scene.fastForwarding() &&
+		time.Now().Sub(b.startTime) > fastForwardDelay {
+		b.stack[0].finish()
+		b.AdvanceDialogue()
+	}
+	if !b.startTime.IsZero() && b.IsDone() {
+		if time.Now().Sub(b.startTime) > b.delay() && lastLog != b.startTime {
 			debug.Println("dialogue timed out; moving on")
 			lastLog = b.startTime
 		}
@@ -90,8 +121,36 @@ func (b *Bubbles) AdvanceDialogue() {
 	b.advanced = true
 }
 
+// Skip shows the rest of the current line if it's still crawling, or else moves on to the next one.
+func (b *Bubbles) Skip() {
+	if b.Empty() || b.IsDrawn() {
+		b.AdvanceDialogue()
+		return
+	}
+	b.stack[0].finish()
+}
+
 func (b *Bubbles) IsDone() bool {
-	return b.advanced || time.Now().Sub(b.startTime) > bubbleDelay
+	last := len(b.stack) > 0 && b.stack[0].pres.Last && b.IsDrawn() // the options can show up with the last line.
+	return b.advanced || last || time.Now().Sub(b.startTime) > b.delay()
+}
+
+// blipEvery is the number of characters which crawl by between blips.
+const blipEvery = 6
+
+// blip plays a blip from the current line's sound as its text crawls.
+func (b *Bubbles) blip() {
+	if len(b.stack) == 0 || len(b.stack[0].pres.Blips) == 0 || b.IsDrawn() {
+		return
+	}
+	l := b.stack[0]
+	if l.charsShown/blipEvery <= l.blips {
+		return
+	}
+	l.blips = l.charsShown / blipEvery
+	p := Resources.GetRandSound(b.scene.Game.ACtx, l.pres.Blips...)
+	p.Rewind()
+	p.Play()
 }
 
 func (b *Bubbles) IsDrawn() bool {
@@ -107,11 +166,15 @@ func (b *Bubbles) DrawTo(screen *ebiten.Image) bool {
 		return true
 	}
 	const padding = 3
+	bounds := b.TextBounds
+	if shake := b.stack[0].pres.Shake; shake > 0 {
+		bounds = bounds.Add(image.Pt(rand.Intn(2*shake+1)-shake, rand.Intn(2*shake+1)-shake))
+	}
 	b.txt.SetTarget(b.offscrn)
-	feed := b.txt.NewFeed(fixed.P(b.TextBounds.Min.X, b.TextBounds.Min.Y))
+	feed := b.txt.NewFeed(fixed.P(bounds.Min.X, bounds.Min.Y))
 	// draw text once offscreen to capture rectangles
 	for _, l := range b.stack {
-		l.Rect = b.print(feed, l, b.TextBounds)
+		l.Rect = b.print(feed, l, bounds)
 	}
 
 	for _, line := range b.stack {
@@ -120,10 +183,10 @@ func (b *Bubbles) DrawTo(screen *ebiten.Image) bool {
 		})
 	}
 	b.txt.SetTarget(screen)
-	feed = b.txt.NewFeed(fixed.P(b.TextBounds.Min.X, b.TextBounds.Min.Y))
+	feed = b.txt.NewFeed(fixed.P(bounds.Min.X, bounds.Min.Y))
 	for _, l := range b.stack {
 		b.txt.SetColor(fontColor)
-		l.Rect = b.print(feed, l, b.TextBounds)
+		l.Rect = b.print(feed, l, bounds)
 	}
 	return b.IsDrawn()
 }
@@ -149,12 +212,23 @@ type Line struct {
 	crawlStart  time.Time
 	charsShown  int
 	highlighted bool
+	pres        Presentation // pres is how the line is presented.
+	blips       int          // blips is the number of blips played so far.
 }
 
 // charsToShow yields the number of characters of the currently displaying text to show based on time since the message
 // was first shown and the crawl speed. The offset provided is subtracted from the result, and can be used to
 func (l *Line) charsToShow() int {
-	return int(time.Now().Sub(l.crawlStart).Seconds() * CrawlSpeedCPS)
+	cps := float64(CrawlSpeedCPS)
+	if l.pres.Pace > 0 {
+		cps *= l.pres.Pace
+	}
+	return crawled(l.pres.Markup, utf8.RuneCountInString(l.Text), time.Now().Sub(l.crawlStart), cps)
+}
+
+// finish shows the rest of the line at once.
+func (l *Line) finish() {
+	l.crawlStart = time.Now().Add(-time.Hour)
 }
 
 func NewLine(text string) *Line {
@@ -165,8 +239,8 @@ func NewLine(text string) *Line {
 }
 
 // NewOption returns a line with a crawlStart of zero.
-func NewOption(text string) *Line {
-	return &Line{Text: text}
+func NewOption(text string, markup []Markup) *Line {
+	return &Line{Text: text, pres: Presentation{Markup: markup}}
 }
 
 const fontSize = 16
@@ -195,6 +269,8 @@ func (b *Bubbles) print(feed *etxt.Feed, line *Line, bounds image.Rectangle) ima
 	if feed == nil {
 		feed = b.txt.NewFeed(fixed.P(bounds.Min.X, bounds.Min.Y-fontSize)) // -fontSize enables choice highlighting.
 	}
+	base := b.txt.GetColor()
+	defer b.txt.SetColor(base)
 	index := 0
 	totalChars := 0
 	for totalChars < charsToShow && index < len(line.Text) {
@@ -225,7 +301,7 @@ func (b *Bubbles) print(feed *etxt.Feed, line *Line, bounds image.Rectangle) ima
 
 			// draw the word and increase index
 			for _, codePoint := range word {
-				feed.Draw(codePoint) // you may want to cut this earlier if the word is too long
+				b.drawRune(feed, line, totalChars, codePoint, base) // you may want to cut this earlier if the word is too long
 				totalChars++
 				if totalChars == charsToShow {
 					break
@@ -238,9 +314,37 @@ func (b *Bubbles) print(feed *etxt.Feed, line *Line, bounds image.Rectangle) ima
 	return used
 }
 
-func max(x, y int) int {
-	if x < y {
-		return y
+// waveSpeed is how fast MarkupWave text bobs, in radians per second; wavePhase is how far behind each letter is.
+const waveSpeed, wavePhase = 6, 0.6
+
+// drawRune draws the rune at idx in the line, styled by any markup over it.
+func (b *Bubbles) drawRune(feed *etxt.Feed, line *Line, idx int, r rune, base color.Color) {
+	b.txt.SetColor(base)
+	var offset fixed.Point26_6
+	bold := false
+	for _, m := range line.pres.Markup {
+		if idx < m.Start || idx >= m.End {
+			continue
+		}
+		switch m.Name {
+		case MarkupBold:
+			bold = true
+		case MarkupColor:
+			b.txt.SetColor(m.Color)
+		case MarkupWave:
+			t := time.Now().Sub(line.crawlStart).Seconds()
+			offset.Y += fixed.I(int(math.Round(float64(m.Size) * math.Sin(t*waveSpeed-float64(idx)*wavePhase))))
+		case MarkupShake:
+			offset = offset.Add(fixed.P(rand.Intn(2*m.Size+1)-m.Size, rand.Intn(2*m.Size+1)-m.Size))
+		}
+	}
+	start := feed.Position
+	feed.Position = start.Add(offset)
+	feed.Draw(r)
+	end := feed.Position.Sub(offset)
+	if bold { // Munro has no bold, so draw it again a pixel over.
+		feed.Position = start.Add(offset).Add(fixed.P(1, 0))
+		feed.Draw(r)
 	}
-	return x
+	feed.Position = end
 }
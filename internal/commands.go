@@ -0,0 +1,276 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParamKind is the type of a parameter to a Yarn command.
+type ParamKind uint8
+
+const (
+	ParamInt   ParamKind = iota // ParamInt is a whole number, zero or more.
+	ParamFloat                  // ParamFloat is an amount, like 12.50.
+	ParamSound                  // ParamSound is the name of a file in gamedata/audio.
+	ParamItem                   // ParamItem is something put_counter can put on the counter; see CounterItem.
+	ParamText                   // ParamText is the rest of the command, spaces and all.
+)
+
+func (k ParamKind) String() string {
+	switch k {
+	case ParamInt:
+		return "int"
+	case ParamFloat:
+		return "amount"
+	case ParamSound:
+		return "sound"
+	case ParamItem:
+		return "item"
+	}
+	return "text"
+}
+
+// Param is a parameter to a Yarn command.
+type Param struct {
+	Name   string
+	Kind   ParamKind
+	Repeat bool // Repeat parameters take all the remaining arguments; they're passed to Run as a slice.
+}
+
+// yarnCommand is a command which can be run from Yarn with << name args... >>, or from the developer console.
+type yarnCommand struct {
+	Name   string
+	Params []Param
+	States []SceneState // States are the states the command can run in; anything else is skipped.
+	Help   string
+	Run    func(m *MainScene, args []any) error
+}
+
+// Usage describes how to call the command.
+func (c *yarnCommand) Usage() string {
+	result := c.Name
+	for _, p := range c.Params {
+		if p.Repeat {
+			result += fmt.Sprintf(" <%s:%s>...", p.Name, p.Kind)
+		} else {
+			result += fmt.Sprintf(" <%s:%s>", p.Name, p.Kind)
+		}
+	}
+	return result
+}
+
+// Parse parses the arguments to the command. Ints come back as int, amounts as float32, items as CounterItem, and
+// repeated parameters as slices of those. Sounds and text come back as strings. If static is set, arguments with
+// substitutions in them (e.g. {0}) haven't been filled in yet and are let through unchecked as strings. Bad arguments to
+// a repeated parameter are left out, and reported in an error wrapping errSkipped; the command can still run.
+func (c *yarnCommand) Parse(tokens []string, static bool) ([]any, error) {
+	var result []any
+	for i, p := range c.Params {
+		switch {
+		case p.Kind == ParamText:
+			if i >= len(tokens) {
+				return nil, fmt.Errorf("%s: missing %s; usage: %s", c.Name, p.Name, c.Usage())
+			}
+			result = append(result, strings.Join(tokens[i:], " "))
+			return result, nil
+		case p.Repeat:
+			var vals []any
+			var bad []string
+			for _, tok := range tokens[i:] {
+				v, err := parseParam(p, tok, static)
+				if err != nil {
+					bad = append(bad, err.Error())
+					continue
+				}
+				vals = append(vals, v)
+			}
+			result = append(result, vals)
+			if len(bad) > 0 {
+				return result, fmt.Errorf("%s: %w %s", c.Name, errSkipped, strings.Join(bad, "; "))
+			}
+			return result, nil
+		case i >= len(tokens):
+			return nil, fmt.Errorf("%s: missing %s; usage: %s", c.Name, p.Name, c.Usage())
+		}
+		v, err := parseParam(p, tokens[i], static)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.Name, err)
+		}
+		result = append(result, v)
+	}
+	if len(tokens) > len(c.Params) {
+		return nil, fmt.Errorf("%s: too many arguments %v; usage: %s", c.Name, tokens[len(c.Params):], c.Usage())
+	}
+	return result, nil
+}
+
+func parseParam(p Param, tok string, static bool) (any, error) {
+	if static && strings.Contains(tok, "{") {
+		return tok, nil
+	}
+	switch p.Kind {
+	case ParamInt:
+		v, err := strconv.Atoi(tok)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("%s must be a whole number; got %q", p.Name, tok)
+		}
+		return v, nil
+	case ParamFloat:
+		v, err := strconv.ParseFloat(tok, 32)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("%s must be an amount; got %q", p.Name, tok)
+		}
+		return float32(v), nil
+	case ParamSound:
+		if _, err := fs.Stat(audioFiles, "gamedata/audio/"+tok); err != nil {
+			return nil, fmt.Errorf("missing sound file %s", tok)
+		}
+		return tok, nil
+	case ParamItem:
+		return parseCounterItem(tok)
+	}
+	return tok, nil
+}
+
+// CounterItem is something put_counter can put on the counter. Items are written as the kind, optionally followed by an
+// amount; e.g. trash, bill_20 or withdrawal_slip_7500.
+type CounterItem struct {
+	Kind   string
+	Amount int // Amount is the denomination of bills and coins, or the value of a slip; -1 if there isn't one.
+}
+
+// counterItems lists the kinds of CounterItem, and the amounts each can have: nil for no amount, or anyAmount.
+var counterItems = map[string][]int{
+	"check": nil, "itemized_slip": nil, "empty_slip": nil, "id": nil, "trash": nil,
+	"deposit_slip": anyAmount, "withdrawal_slip": anyAmount,
+	"bill":  {1, 5, 10, 20, 100},
+	"stack": {1, 5, 10, 20, 100},
+	"coin":  {1, 5, 10, 25, 50},
+}
+
+// anyAmount marks counter items whose amount is optional, and can be anything.
+var anyAmount = []int{}
+
+func parseCounterItem(s string) (CounterItem, error) {
+	if _, ok := counterItems[s]; ok {
+		if len(counterItems[s]) == 0 {
+			return CounterItem{Kind: s, Amount: -1}, nil
+		}
+		return CounterItem{}, fmt.Errorf("%s needs an amount; e.g. %s_%d", s, s, counterItems[s][0])
+	}
+	idx := strings.LastIndex(s, "_")
+	if idx < 0 {
+		return CounterItem{}, fmt.Errorf("unknown item %q", s)
+	}
+	kind := s[:idx]
+	amts, ok := counterItems[kind]
+	amt, err := strconv.Atoi(s[idx+1:])
+	switch {
+	case !ok || amts == nil:
+		return CounterItem{}, fmt.Errorf("unknown item %q", s)
+	case err != nil || amt < 0:
+		return CounterItem{}, fmt.Errorf("bad amount in %q", s)
+	case len(amts) > 0 && !contains(amts, amt):
+		return CounterItem{}, fmt.Errorf("no %s of %d; use one of %v", kind, amt, amts)
+	}
+	return CounterItem{Kind: kind, Amount: amt}, nil
+}
+
+var errUnknownCommand = errors.New("unknown command")
+
+// errSkipped is wrapped by errors for bad arguments which were left out; see Parse.
+var errSkipped = errors.New("skipping")
+
+// lookupCommand finds the command with the provided name.
+func lookupCommand(name string) *yarnCommand {
+	for i := range yarnCommands {
+		if yarnCommands[i].Name == name {
+			return &yarnCommands[i]
+		}
+	}
+	return nil
+}
+
+// parseCommand finds the command for a line like "put_cash 20" and parses its arguments.
+func parseCommand(line string, static bool) (*yarnCommand, []any, error) {
+	tokens := strings.Fields(line)
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("empty command")
+	}
+	cmd := lookupCommand(tokens[0])
+	if cmd == nil {
+		return nil, nil, fmt.Errorf("%w %q", errUnknownCommand, tokens[0])
+	}
+	args, err := cmd.Parse(tokens[1:], static)
+	return cmd, args, err
+}
+
+// items converts a repeated ParamItem argument.
+func items(arg any) []CounterItem {
+	var result []CounterItem
+	for _, v := range arg.([]any) {
+		result = append(result, v.(CounterItem))
+	}
+	return result
+}
+
+var yarnCommands []yarnCommand
+
+func init() {
+	// atWindow commands need a customer at the window, or the dialogue which ends the day; the rest change the bank
+	// itself, and can run any time the teller isn't sending a customer away.
+	atWindow := []SceneState{StateConversing}
+	anytime := []SceneState{StateFadeIn, StateApproaching, StateConversing, StateReporting, StateFadingToNewDay}
+	yarnCommands = []yarnCommand{
+		{Name: "put_counter", Params: []Param{{Name: "items", Kind: ParamItem, Repeat: true}}, States: atWindow,
+			Help: "puts the items on the counter",
+			Run:  func(m *MainScene, args []any) error { return m.putCounter(items(args[0])) }},
+		{Name: "put_cash", Params: []Param{{Name: "dollars", Kind: ParamInt}}, States: atWindow,
+			Help: "puts bills worth the amount on the counter",
+			Run:  func(m *MainScene, args []any) error { return m.putCash(args[0].(int)) }},
+		{Name: "put_coins", Params: []Param{{Name: "cents", Kind: ParamInt}}, States: atWindow,
+			Help: "puts coins worth the amount on the counter",
+			Run:  func(m *MainScene, args []any) error { return m.putCoinsCmd(args[0].(int)) }},
+		{Name: "put_cash_and_coins", Params: []Param{{Name: "amount", Kind: ParamFloat}}, States: atWindow,
+			Help: "puts bills and coins worth the amount on the counter",
+			Run:  func(m *MainScene, args []any) error { return m.putCashAndCoins(args[0].(float32)) }},
+		{Name: "play_sound", Params: []Param{{Name: "file", Kind: ParamSound}}, States: anytime,
+			Help: "plays a sound from gamedata/audio",
+			Run:  func(m *MainScene, args []any) error { return m.playSound(args[0].(string)) }},
+		{Name: "depart", States: atWindow,
+			Help: "sends the customer away",
+			Run:  func(m *MainScene, _ []any) error { return m.depart() }},
+		{Name: "set_wrong", States: atWindow,
+			Help: "puts a mistake on the customer's slip",
+			Run:  func(m *MainScene, _ []any) error { return m.setWrong() }},
+		{Name: "show_reconciliation_report", States: atWindow,
+			Help: "ends the day and waits for the player to read the report",
+			Run:  func(m *MainScene, _ []any) error { return m.showReconciliationReport() }},
+		{Name: "next_day", States: atWindow,
+			Help: "fades to the next day",
+			Run:  func(m *MainScene, _ []any) error { return m.nextDay() }},
+		{Name: "terminal_on", States: anytime,
+			Help: "switches the terminal on",
+			Run:  func(m *MainScene, _ []any) error { m.terminal.Operational = true; return nil }},
+		{Name: "terminal_off", States: anytime,
+			Help: "switches the terminal off",
+			Run:  func(m *MainScene, _ []any) error { m.terminal.Operational = false; return nil }},
+		{Name: "shredder_on", States: anytime,
+			Help: "installs the shredder",
+			Run:  func(m *MainScene, _ []any) error { m.shredder.enable(); return nil }},
+		{Name: "send_memo", Params: []Param{{Name: "text", Kind: ParamText}}, States: anytime,
+			Help: "delivers a memo from the manager to the terminal",
+			Run:  func(m *MainScene, args []any) error { return m.sendMemo(args[0].(string)) }},
+		{Name: "start_robbery", Params: []Param{{Name: "dollars", Kind: ParamInt}}, States: atWindow,
+			Help: "starts a robbery demanding the amount",
+			Run:  func(m *MainScene, args []any) error { return m.startRobbery(args[0].(int)) }},
+		{Name: "resolve_robbery", States: atWindow,
+			Help: "decides how the robbery in progress ends",
+			Run:  func(m *MainScene, _ []any) error { return m.resolveRobbery() }},
+	}
+	sort.Slice(yarnCommands, func(i, j int) bool { return yarnCommands[i].Name < yarnCommands[j].Name })
+}
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseCommand(t *testing.T) {
+	cmd, args, err := parseCommand("put_counter deposit_slip withdrawal_slip_7500 bill_20 id", false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "put_counter", cmd.Name)
+	assert.EqualValues(t, []CounterItem{{"deposit_slip", -1}, {"withdrawal_slip", 7500}, {"bill", 20}, {"id", -1}}, items(args[0]))
+
+	_, args, err = parseCommand("put_cash_and_coins 12.50", false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []any{float32(12.5)}, args)
+
+	_, args, err = parseCommand("send_memo No more  checks.", false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []any{"No more checks."}, args)
+
+	_, _, err = parseCommand("put_cash {0}", true)
+	assert.NoError(t, err)
+	_, _, err = parseCommand("play_sound Bell-1.ogg", false)
+	assert.NoError(t, err)
+
+	_, args, err = parseCommand("put_counter bill_20 chek id", false)
+	assert.ErrorIs(t, err, errSkipped)
+	assert.ErrorContains(t, err, `unknown item "chek"`)
+	assert.EqualValues(t, []CounterItem{{"bill", 20}, {"id", -1}}, items(args[0]))
+
+	for _, bad := range []string{
+		"", "dance", "put_cash", "put_cash -5", "put_cash {0}", "depart now", "play_sound nope.ogg",
+		"put_counter chek", "put_counter bill", "put_counter bill_3", "put_counter deposit_slip_lots", "send_memo",
+	} {
+		_, _, err = parseCommand(bad, false)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestYarnCommand_Usage(t *testing.T) {
+	assert.EqualValues(t, "put_counter <items:item>...", lookupCommand("put_counter").Usage())
+	assert.EqualValues(t, "depart", lookupCommand("depart").Usage())
+}
+
+func TestYarnCommand_States(t *testing.T) {
+	assert.NotContains(t, lookupCommand("put_counter").States, StateApproaching)
+	assert.Contains(t, lookupCommand("send_memo").States, StateApproaching)
+	for _, cmd := range yarnCommands {
+		assert.NotContains(t, cmd.States, StateDismissing, cmd.Name)
+	}
+}
@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/DrJosh9000/yarn"
+	"github.com/Frabjous-Studios/bankwave/internal/debug"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"github.com/tinne26/etxt"
+	"sync"
+)
+
+// Console is a developer console for running Yarn commands by hand; it's only available while debugging. Press ` to
+// open it and type help for a list of commands.
+type Console struct {
+	scene *MainScene
+	txt   *etxt.Renderer
+
+	Open  bool
+	input []rune
+
+	mut   sync.Mutex // mut guards lines, which commands print to from their own goroutines.
+	lines []string
+}
+
+const maxConsoleLines = 12
+const consoleFontSize = 16
+
+var consoleColor = h2c("00ff00")
+
+func NewConsole(txt *etxt.Renderer, scene *MainScene) *Console {
+	return &Console{scene: scene, txt: txt}
+}
+
+func (c *Console) Update() {
+	if !debug.Enabled {
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyGraveAccent) {
+		c.Open = !c.Open
+		c.scene.terminal.Focus(false)
+		return
+	}
+	if !c.Open {
+		return
+	}
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if r != '`' {
+			c.input = append(c.input, r)
+		}
+	}
+	switch {
+	case repeatingKeyPressed(ebiten.KeyBackspace) && len(c.input) > 0:
+		c.input = c.input[:len(c.input)-1]
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter):
+		c.exec(string(c.input))
+		c.input = nil
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		c.Open = false
+	}
+}
+
+// exec runs a line typed into the console. Commands run on their own goroutine, just like they do from Yarn, since
+// some of them wait on the scene.
+func (c *Console) exec(line string) {
+	c.Print("> " + line)
+	switch line {
+	case "":
+		return
+	case "help":
+		for _, cmd := range yarnCommands {
+			c.Print(fmt.Sprintf("%s: %s", cmd.Usage(), cmd.Help))
+		}
+		return
+	}
+	go func() {
+		if err := c.scene.runCommand(line); err != nil && !errors.Is(err, yarn.Stop) {
+			c.Print(err.Error())
+		}
+	}()
+}
+
+// Print appends lines to the console, scrolling old lines away.
+func (c *Console) Print(lines ...string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.lines = append(c.lines, lines...)
+	if len(c.lines) > maxConsoleLines {
+		c.lines = c.lines[len(c.lines)-maxConsoleLines:]
+	}
+}
+
+func (c *Console) DrawTo(screen *ebiten.Image) {
+	if !c.Open {
+		return
+	}
+	const lineHeight = consoleFontSize + 2
+	height := (maxConsoleLines+1)*lineHeight + 10
+
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(float64(screen.Bounds().Dx()), float64(height))
+	opts.ColorScale.Scale(1, 1, 1, 0.8)
+	screen.DrawImage(c.scene.black, opts)
+
+	v, h := c.txt.GetAlign()
+	defer c.txt.SetAlign(v, h)
+	c.txt.SetTarget(screen)
+	c.txt.SetFont(Resources.GetFont(DialogFont))
+	c.txt.SetSizePx(consoleFontSize)
+	c.txt.SetColor(consoleColor)
+	c.txt.SetAlign(etxt.Top, etxt.Left)
+
+	c.mut.Lock()
+	y := 5
+	for _, line := range c.lines {
+		c.txt.Draw(line, 5, y)
+		y += lineHeight
+	}
+	c.mut.Unlock()
+	c.txt.Draw("] "+string(c.input)+"_", 5, height-5-lineHeight)
+}
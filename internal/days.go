@@ -1,15 +1,70 @@
 package internal
 
 import (
+	"errors"
 	"math/rand"
 	"strings"
 	"time"
 )
 
 type Account struct {
-	Owner    string
-	Number   string
-	Checking int
+	Owner        string
+	Number       string
+	Checking     int
+	StartBalance int        // StartBalance is the balance of the account before the teller touched it.
+	Hold         bool       // Hold is set when the teller has frozen the account.
+	History      []*Posting // History of every transaction posted to this account.
+}
+
+// PostingKind is the kind of transaction posted to an account at the terminal.
+type PostingKind string
+
+const (
+	PostDeposit    PostingKind = "DEP"
+	PostWithdrawal PostingKind = "WD"
+	PostCheck      PostingKind = "CHK"
+)
+
+func (k PostingKind) Valid() bool {
+	return k == PostDeposit || k == PostWithdrawal || k == PostCheck
+}
+
+// Posting is a single transaction posted to an account.
+type Posting struct {
+	Acct   string
+	Kind   PostingKind
+	Amount int
+}
+
+// Signed returns the amount of this posting, negative if it takes money out of the account.
+func (p *Posting) Signed() int {
+	if p.Kind == PostWithdrawal {
+		return -p.Amount
+	}
+	return p.Amount
+}
+
+var (
+	ErrAccountOnHold     = errors.New("account on hold")
+	ErrInsufficientFunds = errors.New("insufficient funds")
+)
+
+// Post applies a transaction to this account, recording it in the account history.
+func (a *Account) Post(kind PostingKind, amt int) error {
+	if a.Hold {
+		return ErrAccountOnHold
+	}
+	switch kind {
+	case PostDeposit, PostCheck:
+		a.Checking += amt
+	case PostWithdrawal:
+		if amt > a.Checking {
+			return ErrInsufficientFunds
+		}
+		a.Checking -= amt
+	}
+	a.History = append(a.History, &Posting{Acct: a.Number, Kind: kind, Amount: amt})
+	return nil
 }
 
 type Day struct {
@@ -25,7 +80,19 @@ type Day struct {
 
 	EndNode string
 
-	Accounts map[string]*Account
+	Accounts  map[string]*Account
+	Ledger    Ledger
+	Disposals DisposalAudit // Disposals records everything shredded or thrown away today.
+
+	LongLineWarned bool     // LongLineWarned is set once the manager has complained about the line.
+	StormOffs      []string // StormOffs are the names of customers who got fed up and left.
+
+	Robberies     []*Robbery
+	RobbersCaught int
+	FalseAlarms   int
+
+	// Memos are delivered to the terminal partway through the day.
+	Memos []*ScheduledMemo
 
 	curr int
 }
@@ -46,6 +113,9 @@ func Days() []*Day {
 			Sequence: []string{"Manager_Day3", "random", "random", "random", "drone", "random", "random", "OldMan_Day3"},
 			Random:   []string{"RandomDeposit_Polite", "RandomDeposit_Rude", "RandomCheck_Polite", "RandomCheck_Rude", "RandomWithdrawal_Polite", "RandomWithdrawal_Rude"},
 			EndNode:  "Manager_Day3_End",
+			Memos: []*ScheduledMemo{
+				{At: DayLength / 2, From: "memo.from_manager", Text: "memo.no_big_checks"},
+			},
 		},
 		3: {
 			Sequence: []string{"Manager_Day4", "random", "random", "Janitor_2", "random", "drone", "random", "random", "OldMan_Day4"},
@@ -58,7 +128,7 @@ func Days() []*Day {
 			EndNode:  "Manager_Day5_End",
 		},
 		5: {
-			Sequence: []string{"Manager_Day6", "random", "random", "random", "drone", "random", "random", "Karen_Day6", "OldMan_Day6"},
+			Sequence: []string{"Manager_Day6", "random", "random", "random", "drone", "random", "Robber", "random", "Karen_Day6", "OldMan_Day6"},
 			Random:   []string{"RandomDeposit_Polite", "RandomDeposit_Rude", "RandomCheck_Polite", "RandomCheck_Rude", "RandomWithdrawal_Polite", "RandomWithdrawal_Rude"},
 			EndNode:  "Manager_Day6_End",
 		},
@@ -101,6 +171,52 @@ func (d *Day) Next(t time.Duration) string {
 	return d.Sequence[curr]
 }
 
+// QueueAtOpen is the number of customers already waiting when the doors open, not counting the manager.
+const QueueAtOpen = 2
+
+// ExtraArrivals is the number of unscheduled customers expected to show up over the course of a day.
+const ExtraArrivals = 4
+
+// ArrivalInterval is the time between customers arriving in the lobby. The scheduled customers, plus a few extra,
+// arrive spread out evenly over the day.
+func (d *Day) ArrivalInterval() time.Duration {
+	return DayLength / time.Duration(len(d.Sequence)+ExtraArrivals)
+}
+
+// Waiting is the number of customers waiting in line, given the amount of time spent on this day. Nobody new gets in
+// line once it's time for the manager, and nobody waits who isn't scheduled.
+func (d *Day) Waiting(t time.Duration) int {
+	if t >= DayLength {
+		return 0
+	}
+	arrived := 1 + QueueAtOpen + int(t/d.ArrivalInterval()) // the manager shows up first, and doesn't wait in line.
+	return max(0, min(arrived, len(d.Sequence))-d.curr)
+}
+
+// DueMemos returns any scheduled memos which are due to arrive, given the amount of time spent on this day. Each memo is
+// only returned once.
+func (d *Day) DueMemos(t time.Duration) []*Memo {
+	var result []*Memo
+	for _, m := range d.Memos {
+		if !m.sent && t >= m.At {
+			m.sent = true
+			result = append(result, &Memo{From: T(m.From), Text: T(m.Text)})
+		}
+	}
+	return result
+}
+
+// AcceptSlip records a slip accepted into the till, along with the postings the teller now owes the ledger.
 func (d *Day) AcceptSlip(slip *DepositSlip) {
 	d.Slips = append(d.Slips, slip)
+	d.Ledger.Expect(slip)
+}
+
+// Post posts a transaction to the provided account and records it in today's ledger.
+func (d *Day) Post(acct *Account, kind PostingKind, amt int) error {
+	if err := acct.Post(kind, amt); err != nil {
+		return err
+	}
+	d.Ledger.Record(acct.History[len(acct.History)-1])
+	return nil
 }
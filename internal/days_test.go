@@ -22,3 +22,79 @@ func TestDays_Next(t *testing.T) {
 		assert.True(t, strings.HasPrefix(day.Next(time.Second), "rand"))
 	}
 }
+
+func TestDay_Waiting(t *testing.T) {
+	day := Day{Sequence: []string{"a", "b", "c", "d"}} // 8 arrivals over the day
+
+	day.Next(0) // the manager
+	assert.EqualValues(t, QueueAtOpen, day.Waiting(0))
+	assert.EqualValues(t, QueueAtOpen+1, day.Waiting(DayLength/8))
+	assert.EqualValues(t, 3, day.Waiting(DayLength/4), "only the rest of the schedule waits")
+
+	day.Next(0)
+	assert.EqualValues(t, QueueAtOpen, day.Waiting(DayLength/8))
+	assert.EqualValues(t, 2, day.Waiting(DayLength/4))
+	assert.EqualValues(t, 0, day.Waiting(DayLength))
+}
+
+func TestAccount_Post(t *testing.T) {
+	acct := &Account{Number: "12345", Checking: 1000}
+
+	assert.NoError(t, acct.Post(PostDeposit, 500))
+	assert.EqualValues(t, 1500, acct.Checking)
+
+	assert.ErrorIs(t, acct.Post(PostWithdrawal, 2000), ErrInsufficientFunds)
+	assert.NoError(t, acct.Post(PostWithdrawal, 1500))
+	assert.EqualValues(t, 0, acct.Checking)
+
+	acct.Hold = true
+	assert.ErrorIs(t, acct.Post(PostCheck, 100), ErrAccountOnHold)
+	assert.Len(t, acct.History, 2)
+}
+
+func TestLedger_Reconcile(t *testing.T) {
+	day := Day{Accounts: map[string]*Account{
+		"12345": {Number: "12345", Checking: 1000, StartBalance: 1000},
+		"54321": {Number: "54321", Checking: 1000, StartBalance: 1000},
+	}}
+	day.AcceptSlip(&DepositSlip{AcctNum: 12345, ForDeposit: true, Value: 500})
+	day.AcceptSlip(&DepositSlip{AcctNum: 54321, ForWithdrawal: true, IsWithdrawal: true, Value: 200})
+	assert.Len(t, day.Ledger.Unposted(), 2)
+
+	assert.NoError(t, day.Post(day.Accounts["12345"], PostDeposit, 500))
+	assert.NoError(t, day.Post(day.Accounts["54321"], PostDeposit, 200)) // oops!
+
+	report := day.Ledger.Reconcile(day.Accounts)
+	assert.EqualValues(t, 1, report.Unposted)
+	assert.EqualValues(t, 1, report.Misposted)
+	assert.EqualValues(t, 1, report.BalancesOff)
+}
+
+func TestLedger_UnpostedThisVisit(t *testing.T) {
+	day := Day{Accounts: map[string]*Account{
+		"12345": {Number: "12345", Checking: 1000, StartBalance: 1000},
+	}}
+	day.AcceptSlip(&DepositSlip{AcctNum: 12345, ForDeposit: true, Value: 500})
+	day.Ledger.StartVisit()
+	assert.Empty(t, day.Ledger.UnpostedThisVisit(), "the last customer's mistakes are theirs")
+
+	day.Ledger.ExpectCheck(day.Accounts["12345"], &Check{Value: 300})
+	assert.EqualValues(t, []*Posting{{Acct: "12345", Kind: PostCheck, Amount: 300}}, day.Ledger.UnpostedThisVisit())
+	assert.Len(t, day.Ledger.Unposted(), 2)
+
+	assert.NoError(t, day.Post(day.Accounts["12345"], PostCheck, 300))
+	assert.Empty(t, day.Ledger.UnpostedThisVisit())
+}
+
+func TestMainScene_SetupAccount(t *testing.T) {
+	m := &MainScene{Day: &Day{Accounts: map[string]*Account{
+		"12345": {Number: "12345", Checking: 1000, StartBalance: 1000},
+	}}, Customer: &Customer{CustomerName: "Bob"}}
+
+	m.setupAccount(&DepositSlip{AcctNum: 12345, ForWithdrawal: true, IsWithdrawal: true, Value: 99900})
+	m.setupAccount(&DepositSlip{AcctNum: 54321, ForWithdrawal: true, IsWithdrawal: true, Value: 99900})
+	for _, acct := range m.Day.Accounts {
+		assert.NoError(t, acct.Post(PostWithdrawal, 99900), acct.Number)
+		assert.EqualValues(t, acct.StartBalance-99900, acct.Checking, acct.Number)
+	}
+}
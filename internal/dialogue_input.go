@@ -0,0 +1,99 @@
+package internal
+
+import (
+	"github.com/Frabjous-Studios/bankwave/internal/debug"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"time"
+)
+
+var gamepadIDs []ebiten.GamepadID
+
+// updateDialogueInput lets the player drive dialogue from the keyboard or a gamepad, as well as the mouse:
+//
+//	1-9               choose an option
+//	Up/Down, D-pad    highlight an option
+//	Enter/Space, A    choose the highlighted option, or else show the rest of the line, or else move on
+//	S, X              toggle fast-forward, which skips lines already seen; hold Ctrl or RB to fast-forward for a moment
+func (m *MainScene) updateDialogueInput() {
+	if m.CapturingText() {
+		return
+	}
+	if inpututil.IsKeyJustPressed(ebiten.KeyS) || gamepadJustPressed(ebiten.StandardGamepadButtonRightLeft) {
+		m.fastForward = !m.fastForward
+		debug.Println("fast-forward:", m.fastForward)
+	}
+	if m.optionsShown() {
+		for i := range m.options {
+			if i < 9 && (inpututil.IsKeyJustPressed(ebiten.KeyDigit1+ebiten.Key(i)) ||
+				inpututil.IsKeyJustPressed(ebiten.KeyNumpad1+ebiten.Key(i))) {
+				m.chooseOption(i)
+				return
+			}
+		}
+		switch {
+		case repeatingKeyPressed(ebiten.KeyArrowUp) || gamepadJustPressed(ebiten.StandardGamepadButtonLeftTop):
+			m.optionIdx = (max(m.optionIdx, 0) + len(m.options) - 1) % len(m.options)
+		case repeatingKeyPressed(ebiten.KeyArrowDown) || gamepadJustPressed(ebiten.StandardGamepadButtonLeftBottom):
+			m.optionIdx = (m.optionIdx + 1) % len(m.options)
+		}
+	}
+	if confirmPressed() {
+		if m.optionsShown() && m.optionIdx >= 0 && m.optionIdx < len(m.options) {
+			m.chooseOption(m.optionIdx)
+			return
+		}
+		m.bubbles.Skip()
+	}
+}
+
+// optionsShown is true while the player can choose a dialogue option.
+func (m *MainScene) optionsShown() bool {
+	return len(m.options) > 0 && (m.bubbles.Empty() || m.bubbles.IsDrawn())
+}
+
+// chooseOption sends the option with the provided index to the dialogue runner, if it's waiting for one.
+func (m *MainScene) chooseOption(idx int) {
+	select {
+	case m.dialogueOptions <- idx:
+		debug.Println("player dialogue option was sent:", idx)
+	default:
+		debug.Println("dialogue runner wasn't waiting for an option")
+	}
+	m.optionIdx = -1
+	m.debouceTime = time.Now().Add(debounceDuration)
+}
+
+// fastForwarding is true while lines already seen should be skipped.
+func (m *MainScene) fastForwarding() bool {
+	return !m.CapturingText() && (m.fastForward || ebiten.IsKeyPressed(ebiten.KeyControl) ||
+		gamepadPressed(ebiten.StandardGamepadButtonFrontTopRight))
+}
+
+// confirmPressed is true when the player just pressed Enter, Space, or A.
+func confirmPressed() bool {
+	return inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter) ||
+		inpututil.IsKeyJustPressed(ebiten.KeySpace) || gamepadJustPressed(ebiten.StandardGamepadButtonRightBottom)
+}
+
+// gamepadJustPressed is true when the button was just pressed on any gamepad with a standard layout.
+func gamepadJustPressed(button ebiten.StandardGamepadButton) bool {
+	gamepadIDs = ebiten.AppendGamepadIDs(gamepadIDs[:0])
+	for _, id := range gamepadIDs {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) && inpututil.IsStandardGamepadButtonJustPressed(id, button) {
+			return true
+		}
+	}
+	return false
+}
+
+// gamepadPressed is true while the button is held on any gamepad with a standard layout.
+func gamepadPressed(button ebiten.StandardGamepadButton) bool {
+	gamepadIDs = ebiten.AppendGamepadIDs(gamepadIDs[:0])
+	for _, id := range gamepadIDs {
+		if ebiten.IsStandardGamepadLayoutAvailable(id) && ebiten.IsStandardGamepadButtonPressed(id, button) {
+			return true
+		}
+	}
+	return false
+}
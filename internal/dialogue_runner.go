@@ -7,13 +7,15 @@ import (
 	"github.com/DrJosh9000/yarn/bytecode"
 	"github.com/Frabjous-Studios/bankwave/internal/debug"
 	"github.com/hajimehoshi/ebiten/v2"
-	"github.com/razor-1/localizer-cldr/resources/language"
+	"strconv"
 	"strings"
 	"sync"
 )
 
 const yarnFile = "gamedata/yarn/bin/game"
 
+//go:generate go run ../cmd/yarnc -n game -o gamedata/yarn/bin gamedata/yarn
+
 // yarnBin contains all yarn output from this compilation process.
 //
 //go:embed gamedata/yarn/bin
@@ -37,8 +39,9 @@ type DialogueRunner struct {
 	runState     RunnerState // runState is manipulated by handler
 	CurrNodeName string      // CurrNodeName is the name of the currently running node.
 
-	mut *sync.RWMutex
-	vm  *yarn.VirtualMachine // vm is the Yarn virtual machine.
+	mut  *sync.RWMutex
+	vm   *yarn.VirtualMachine    // vm is the Yarn virtual machine.
+	vars yarn.MapVariableStorage // vars are the VM's variables; only touch them while holding mut.
 
 	portraitImg *ebiten.Image
 	customer    *Customer
@@ -46,26 +49,30 @@ type DialogueRunner struct {
 	dialogueLines   chan string
 	dialogueOptions chan int
 
-	fullName, firstName, lastName string
-
 	running bool
 }
 
-func NewDialogueRunner(vars yarn.MapVariableStorage, handler yarn.DialogueHandler) (*DialogueRunner, error) {
-	program, st, err := yarn.LoadFilesFS(yarnBin, yarnFile+".yarnc", language.EN_US)
+// NewDialogueRunner creates a runner for the game's Yarn program, which can call the provided functions.
+func NewDialogueRunner(vars yarn.MapVariableStorage, handler yarn.DialogueHandler, funcs yarn.FuncMap) (*DialogueRunner, error) {
+	program, st, err := yarn.LoadFilesFS(yarnBin, yarnFile+".yarnc", Lang.Code)
 	if err != nil {
 		return nil, err
 	}
+	if err := Lang.Lines(st); err != nil {
+		return nil, err
+	}
 	r := &DialogueRunner{
 		program:     program,
 		stringTable: st,
 		runState:    RunnerStopped,
 		mut:         &sync.RWMutex{},
+		vars:        vars,
 	}
 	r.vm = &yarn.VirtualMachine{
 		Program: r.program,
 		Handler: handler,
-		Vars:    vars,
+		Vars:    syncVars{mut: r.mut, vars: vars},
+		FuncMap: funcs,
 	}
 	return r, nil
 }
@@ -76,54 +83,62 @@ const (
 	VarLastName      = "$char_last_name"
 	VarSlipAmt       = "$slip_amount"
 	VarAccountNumber = "$account_number"
+
+	VarCashDestroyed      = "$cash_destroyed" // VarCashDestroyed is the dollar value of genuine cash destroyed today.
+	VarValidDocsDestroyed = "$valid_docs_destroyed"
+	VarEvidenceDestroyed  = "$evidence_destroyed"
+	VarIDsDestroyed       = "$ids_destroyed"
 )
 
 // DoNode starts the runner, which blocks the current thread until a fatal error occurs.
 func (r *DialogueRunner) DoNode(name string) error {
-	// TODO: this should generate a new customer picture and identity before starting *anything* so we can avoid race conditions.
 	defer func() {
 		r.runState = RunnerStopped
 	}()
-	debug.Println("doing node; clearing DialogueRunner customer")
 	r.CurrNodeName = name
 	r.running = true
-	r.customer = nil
 	r.runState = RunnerRunning
 
 	return r.vm.Run(name)
 }
 
+// randomFullName draws a random name without assigning it to anyone.
+func randomFullName() string {
+	return fmt.Sprintf("%s %s", drawRandom(Resources.GetList("first_names.txt")), drawRandom(Resources.GetList("last_names.txt")))
+}
+
 func (r *DialogueRunner) RandomName() string {
-	f, l := drawRandom(Resources.GetList("first_names.txt")), drawRandom(Resources.GetList("last_names.txt"))
+	fullName := randomFullName()
+	r.SetFullName(fullName)
+	return fullName
+}
 
+// SetFullName sets the name of the current customer; everything after the first name is their last name.
+func (r *DialogueRunner) SetFullName(name string) {
 	r.mut.Lock()
 	defer r.mut.Unlock()
-	fullName := fmt.Sprintf("%s %s", f, l)
-	r.fullName = f
-	r.lastName = l
-	r.fullName = fullName
-	//r.vm.Vars.SetValue(VarFirstName, f) TODO: use once we can edit content again
-	//r.vm.Vars.SetValue(VarLastName, f)
-	//r.vm.Vars.SetValue(VarFullName, fullName)
-	return fullName
+	first, last, _ := strings.Cut(name, " ")
+	r.vars.SetValue(VarFirstName, first)
+	r.vars.SetValue(VarLastName, last)
+	r.vars.SetValue(VarFullName, name)
 }
 
 func (r *DialogueRunner) FullName() string {
-	return r.fullName // TODO: r.getString(VarFullName)
+	return r.getString(VarFullName)
 }
 
 func (r *DialogueRunner) FirstName() string {
-	return r.firstName // TODO: r.getString(VarFirstName)
+	return r.getString(VarFirstName)
 }
 
 func (r *DialogueRunner) LastName() string {
-	return r.lastName // TODO: r.getString(VarLastName)
+	return r.getString(VarLastName)
 }
 
 // SetDepositSlip sets variables associated with the generated deposit slip.
 func (r *DialogueRunner) SetDepositSlip(slip *DepositSlip) {
-	//r.SetDepositAmt(slip.Value)  TODO: use after jam, when we can update content again
-	//r.SetAccountNumber(slip.AcctNum)
+	r.SetDepositAmt(slip.Value)
+	r.SetAccountNumber(slip.AcctNum)
 	if r.customer != nil {
 		r.customer.DepositSlip = slip
 	}
@@ -132,13 +147,57 @@ func (r *DialogueRunner) SetDepositSlip(slip *DepositSlip) {
 func (r *DialogueRunner) SetDepositAmt(val int) {
 	r.mut.Lock()
 	defer r.mut.Unlock()
-	r.vm.Vars.SetValue(VarSlipAmt, fmt.Sprintf("%d.%02d", val/100, val%100))
+	r.vars.SetValue(VarSlipAmt, fmtCents(val))
 }
 func (r *DialogueRunner) SetAccountNumber(val int) {
 	r.mut.Lock()
 	defer r.mut.Unlock()
 
-	r.vm.Vars.SetValue(VarAccountNumber, val)
+	r.vars.SetValue(VarAccountNumber, strconv.Itoa(val))
+}
+
+// SetVar sets the value of a Yarn variable.
+func (r *DialogueRunner) SetVar(name string, val interface{}) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.vars.SetValue(name, val)
+}
+
+// SetDisposals exposes today's disposal audit to the manager's end-of-day dialogue.
+func (r *DialogueRunner) SetDisposals(a *DisposalAudit) {
+	r.mut.Lock()
+	defer r.mut.Unlock()
+
+	r.vars.SetValue(VarCashDestroyed, float32(a.CashDestroyed())/100)
+	r.vars.SetValue(VarValidDocsDestroyed, float32(a.ValidDocs))
+	r.vars.SetValue(VarEvidenceDestroyed, float32(a.Evidence))
+	r.vars.SetValue(VarIDsDestroyed, float32(a.IDs))
+}
+
+// syncVars are Yarn variables guarded by the runner's mutex; the VM reads them from its own goroutine while the game
+// sets them from the main goroutine.
+type syncVars struct {
+	mut  *sync.RWMutex
+	vars yarn.MapVariableStorage
+}
+
+func (s syncVars) Clear() {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.vars.Clear()
+}
+
+func (s syncVars) GetValue(name string) (interface{}, bool) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	return s.vars.GetValue(name)
+}
+
+func (s syncVars) SetValue(name string, value interface{}) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+	s.vars.SetValue(name, value)
 }
 
 // CustomerIntent gets the intent set for this node.
@@ -159,6 +218,8 @@ func (r *DialogueRunner) CustomerIntent(currNode string) Intent {
 				return IntentDepositCheck
 			case IntentWithdraw:
 				return IntentWithdraw
+			case IntentRobbery:
+				return IntentRobbery
 			default:
 				debug.Printf("Unknown intent '%s' in node %s", h.Value, r.CurrNodeName)
 			}
@@ -183,15 +244,22 @@ func (r *DialogueRunner) Customer(nodeID string) (p *Customer) {
 			p.CustomerIntent = r.CustomerIntent(nodeID)
 			p.CustomerName = r.RandomName()
 			p.IsRude = strings.Contains(strings.ToLower(nodeID), "rude")
+			p.Patience = 1
+			p.Mood = startingMood(p.IsRude)
+			p.Walk = walkStyle(nodeID)
 		}
 	}()
+	var preferred []string
+	if strings.Contains(strings.ToLower(nodeID), "rude") {
+		preferred = []string{TagRude}
+	}
 	portraitID := r.PortraitID(nodeID)
 	if portraitID == "" {
 		debug.Println("missing portraitID in node", nodeID)
 		portraitID = "random"
 	}
 	if portraitID == "random" {
-		return newRandPortrait()
+		return newRandPortrait(nil, preferred)
 	}
 	toks := strings.Split(portraitID, ":")
 	if len(toks) == 1 {
@@ -199,31 +267,47 @@ func (r *DialogueRunner) Customer(nodeID string) (p *Customer) {
 	}
 	if len(toks) != 2 {
 		debug.Printf("malformed customer portraitID! using random: %v", portraitID)
-		return newRandPortrait()
+		return newRandPortrait(nil, preferred)
+	}
+	if toks[0] == "random" { // random:tag1,tag2
+		return newRandPortrait(strings.Split(toks[1], ","), preferred)
 	}
 	head, body := toks[0], toks[1]
 	return newPortrait(body, head)
 }
 
-func (r *DialogueRunner) IsLastLine(line yarn.Line) bool {
-	if _, ok := r.stringTable.Table[line.ID]; !ok {
-		return false
+// Presentation works out how to show the provided line from the headers of the current node and the line's tags.
+func (r *DialogueRunner) Presentation(line yarn.Line) Presentation {
+	var p Presentation
+	if node, ok := r.vm.Program.Nodes[r.CurrNodeName]; ok {
+		for _, h := range node.Headers {
+			if err := p.Apply(h.Key, h.Value); err != nil {
+				debug.Printf("bad header in node %s: %v", r.CurrNodeName, err)
+			}
+		}
 	}
-	for _, tag := range r.stringTable.Table[line.ID].Tags {
-		if tag == "lastline" {
-			return true
+	if row, ok := r.stringTable.Table[line.ID]; ok {
+		for _, tag := range row.Tags {
+			if err := p.ApplyTag(tag); err != nil {
+				debug.Printf("bad tag on line %s: %v", line.ID, err)
+			}
 		}
 	}
-	return false
+	return p
 }
 
-func (r *DialogueRunner) Render(line yarn.Line) string {
+// Render renders the text of a line, along with the markup styling it.
+func (r *DialogueRunner) Render(line yarn.Line) (string, []Markup) {
 	s, err := r.stringTable.Render(line)
 	if err != nil {
 		debug.Println("error rendering line", line)
-		return "ERROR"
+		return "ERROR", nil
+	}
+	markup, err := parseMarkup(s)
+	if err != nil {
+		debug.Printf("bad markup in line %s: %v", line.ID, err)
 	}
-	return s.String()
+	return s.String(), markup
 }
 
 func portrait(node *bytecode.Node) string {
@@ -242,9 +326,9 @@ func (r *DialogueRunner) getString(varName string) string {
 	r.mut.RLock()
 	defer r.mut.RUnlock()
 
-	v, ok := r.vm.Vars.GetValue(VarFullName)
+	v, ok := r.vars.GetValue(varName)
 	if !ok {
 		return ""
 	}
-	return v.(string)
+	return yarn.ConvertToString(v)
 }
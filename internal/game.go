@@ -20,6 +20,7 @@ type Game struct {
 	Width     int
 	Height    int
 	CurrScene Scene
+	Settings  Settings
 
 	ACtx *audio.Context
 
@@ -30,6 +31,21 @@ type Game struct {
 	incomingVolume  *resound.Volume
 
 	fadeStart time.Time
+
+	seenMut   sync.Mutex      // seenMut guards seenLines, which every game's dialogue goroutine records lines into.
+	seenLines map[string]bool // seenLines are the IDs of the lines of dialogue shown since the game was launched.
+}
+
+// sawLine records that the line with the provided ID has been shown, and reports whether it was shown before.
+func (g *Game) sawLine(id string) bool {
+	g.seenMut.Lock()
+	defer g.seenMut.Unlock()
+	if g.seenLines == nil {
+		g.seenLines = make(map[string]bool)
+	}
+	seen := g.seenLines[id]
+	g.seenLines[id] = true
+	return seen
 }
 
 type Scene interface {
@@ -37,6 +53,17 @@ type Scene interface {
 	Draw(*ebiten.Image)
 }
 
+// TextCapturer is implemented by scenes which sometimes need every key the player types, so global hotkeys must be
+// ignored.
+type TextCapturer interface {
+	CapturingText() bool
+}
+
+func (g *Game) capturingText() bool {
+	tc, ok := g.CurrScene.(TextCapturer)
+	return ok && tc.CapturingText()
+}
+
 // PlayMusic fades out the last track that was playing and fades in a new track
 func (g *Game) PlayMusic(file string) {
 	if g.playingFilename == file {
@@ -80,12 +107,12 @@ func (g *Game) Update() error {
 	})
 
 	// Pressing Q any time quits immediately
-	if ebiten.IsKeyPressed(ebiten.KeyQ) {
+	if ebiten.IsKeyPressed(ebiten.KeyQ) && !g.capturingText() {
 		return errors.New("game quit by player")
 	}
 
 	// Pressing F toggles full-screen
-	if inpututil.IsKeyJustPressed(ebiten.KeyF) {
+	if inpututil.IsKeyJustPressed(ebiten.KeyF) && !g.capturingText() {
 		if ebiten.IsFullscreen() {
 			ebiten.SetFullscreen(false)
 		} else {
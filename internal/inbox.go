@@ -0,0 +1,171 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Memo is a message delivered to the terminal's inbox.
+type Memo struct {
+	From string
+	Text string
+	Read bool
+}
+
+// ScheduledMemo is a memo delivered partway through the day.
+type ScheduledMemo struct {
+	At   time.Duration // At is the amount of time into the day when the memo arrives.
+	From string        // From is a key in the UI catalog, like Text.
+	Text string
+	sent bool
+}
+
+const memoWrapWidth = maxTerminalCols
+const memoLinesShown = maxTerminalLines - 1
+
+// Deliver adds a memo to the inbox and beeps so the player notices.
+func (t *Terminal) Deliver(memo *Memo) {
+	t.Inbox = append(t.Inbox, memo)
+	if t.Operational {
+		snd := Resources.GetSound(t.scene.Game.ACtx, "Computer_Beep_Short-1.ogg")
+		snd.Rewind()
+		snd.Play()
+	}
+}
+
+// Unread counts the unread memos in the inbox.
+func (t *Terminal) Unread() int {
+	var result int
+	for _, memo := range t.Inbox {
+		if !memo.Read {
+			result++
+		}
+	}
+	return result
+}
+
+// mail switches the terminal into its list view, scrolled to the first unread memo.
+func (t *Terminal) mail(_ []string) {
+	if len(t.Inbox) == 0 {
+		t.Print(T("terminal.inbox_empty"))
+		return
+	}
+	t.listing = true
+	t.listOffset = len(t.Inbox)
+	for idx, memo := range t.Inbox {
+		if !memo.Read {
+			t.listOffset = idx
+			break
+		}
+	}
+	t.listOffset = clamp(t.listOffset, 0, max(0, len(t.Inbox)-memoLinesShown))
+}
+
+// handleListKeys scrolls the list of memos, or closes the list view.
+func (t *Terminal) handleListKeys() {
+	switch {
+	case repeatingKeyPressed(ebiten.KeyArrowUp):
+		t.listOffset = clamp(t.listOffset-1, 0, max(0, len(t.Inbox)-memoLinesShown))
+	case repeatingKeyPressed(ebiten.KeyArrowDown):
+		t.listOffset = clamp(t.listOffset+1, 0, max(0, len(t.Inbox)-memoLinesShown))
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		t.listing = false
+	}
+}
+
+// listLines returns the lines shown in the list view.
+func (t *Terminal) listLines() []string {
+	end := min(len(t.Inbox), t.listOffset+memoLinesShown)
+	result := []string{T("terminal.inbox", len(t.Inbox))}
+	for idx := t.listOffset; idx < end; idx++ {
+		mark := " "
+		if !t.Inbox[idx].Read {
+			mark = "*"
+		}
+		result = append(result, fmt.Sprintf("%s%d %s", mark, idx+1, truncate(t.Inbox[idx].Text, memoWrapWidth-4)))
+	}
+	return result
+}
+
+func (t *Terminal) read(args []string) {
+	if len(args) == 0 { // read the oldest unread memo
+		for _, memo := range t.Inbox {
+			if !memo.Read {
+				t.openMemo(memo)
+				return
+			}
+		}
+		t.Print(T("terminal.no_unread"))
+		return
+	}
+	idx, err := strconv.Atoi(args[0])
+	if err != nil || idx < 1 || idx > len(t.Inbox) {
+		t.Print(T("terminal.no_such_memo", args[0]))
+		return
+	}
+	t.openMemo(t.Inbox[idx-1])
+}
+
+// openMemo switches the terminal into its reader view.
+func (t *Terminal) openMemo(memo *Memo) {
+	memo.Read = true
+	t.reading = memo
+	t.readOffset = 0
+}
+
+// handleReaderKeys scrolls the memo being read, or closes the reader view.
+func (t *Terminal) handleReaderKeys() {
+	lines := wrapText(t.reading.Text, memoWrapWidth)
+	switch {
+	case repeatingKeyPressed(ebiten.KeyArrowUp):
+		t.readOffset = clamp(t.readOffset-1, 0, max(0, len(lines)-memoLinesShown))
+	case repeatingKeyPressed(ebiten.KeyArrowDown):
+		t.readOffset = clamp(t.readOffset+1, 0, max(0, len(lines)-memoLinesShown))
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape) || inpututil.IsKeyJustPressed(ebiten.KeyEnter):
+		t.reading = nil
+	}
+}
+
+// readerLines returns the lines shown in the reader view.
+func (t *Terminal) readerLines() []string {
+	lines := wrapText(t.reading.Text, memoWrapWidth)
+	end := min(len(lines), t.readOffset+memoLinesShown)
+	return append([]string{T("terminal.from", t.reading.From)}, lines[t.readOffset:end]...)
+}
+
+// wrapText breaks the provided text into lines no longer than width characters, splitting on spaces.
+func wrapText(text string, width int) []string {
+	var (
+		result []string
+		curr   string
+	)
+	for _, word := range strings.Fields(text) {
+		if curr == "" {
+			curr = word
+		} else if len(curr)+1+len(word) <= width {
+			curr += " " + word
+		} else {
+			result = append(result, curr)
+			curr = word
+		}
+		for len(curr) > width { // words longer than the line get broken
+			result = append(result, curr[:width])
+			curr = curr[width:]
+		}
+	}
+	if curr != "" {
+		result = append(result, curr)
+	}
+	return result
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-1] + "~"
+}
@@ -0,0 +1,129 @@
+package internal
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Ledger tracks the transactions the teller should have posted at the terminal today, along with those they actually
+// posted.
+type Ledger struct {
+	Expected []*Posting
+	Posted   []*Posting
+
+	visitStart int // visitStart is the index of the first posting expected from the customer at the window.
+}
+
+// StartVisit marks the start of a new customer's visit; see UnpostedThisVisit.
+func (l *Ledger) StartVisit() {
+	l.visitStart = len(l.Expected)
+}
+
+// ExpectCheck records the posting needed for a check which was cashed for the owner of the provided account.
+func (l *Ledger) ExpectCheck(acct *Account, check *Check) {
+	l.Expected = append(l.Expected, &Posting{Acct: acct.Number, Kind: PostCheck, Amount: check.Value})
+}
+
+// Expect records the postings needed for the provided slip, which was accepted into the till.
+func (l *Ledger) Expect(slip *DepositSlip) {
+	acct := strconv.Itoa(slip.AcctNum)
+	switch {
+	case slip.IsWithdrawal:
+		l.Expected = append(l.Expected, &Posting{Acct: acct, Kind: PostWithdrawal, Amount: slip.Value})
+	case slip.ForDeposit && slip.Itemized:
+		if slip.Cash > 0 {
+			l.Expected = append(l.Expected, &Posting{Acct: acct, Kind: PostDeposit, Amount: slip.Cash})
+		}
+		for _, check := range slip.Checks {
+			l.Expected = append(l.Expected, &Posting{Acct: acct, Kind: PostCheck, Amount: check})
+		}
+	case slip.ForDeposit:
+		l.Expected = append(l.Expected, &Posting{Acct: acct, Kind: PostDeposit, Amount: slip.Value})
+	}
+}
+
+// Record records a posting made at the terminal.
+func (l *Ledger) Record(p *Posting) {
+	l.Posted = append(l.Posted, p)
+}
+
+// match pairs every expected posting with a posting that was made for the same account, kind, and amount. Anything
+// left over is returned.
+func (l *Ledger) match() (unposted, misposted []*Posting) {
+	used := make(map[*Posting]bool)
+	for _, e := range l.Expected {
+		found := false
+		for _, p := range l.Posted {
+			if !used[p] && *p == *e {
+				used[p] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			unposted = append(unposted, e)
+		}
+	}
+	for _, p := range l.Posted {
+		if !used[p] {
+			misposted = append(misposted, p)
+		}
+	}
+	return unposted, misposted
+}
+
+// Unposted returns every expected posting which hasn't been made yet.
+func (l *Ledger) Unposted() []*Posting {
+	unposted, _ := l.match()
+	return unposted
+}
+
+// UnpostedThisVisit returns the expected postings from the customer at the window which haven't been made yet.
+func (l *Ledger) UnpostedThisVisit() []*Posting {
+	var result []*Posting
+	for _, p := range l.Unposted() {
+		if contains(l.Expected[l.visitStart:], p) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
+
+type LedgerReport struct {
+	Unposted    int
+	Misposted   int
+	BalancesOff int // BalancesOff is the number of accounts whose balance differs from what the ledger expects.
+}
+
+// Reconcile compares the expected postings against what was posted, and the expected balance of each account against
+// its actual balance.
+func (l *Ledger) Reconcile(accounts map[string]*Account) LedgerReport {
+	unposted, misposted := l.match()
+	report := LedgerReport{
+		Unposted:  len(unposted),
+		Misposted: len(misposted),
+	}
+	expected := make(map[string]int)
+	for _, e := range l.Expected {
+		if _, ok := expected[e.Acct]; !ok {
+			if acct, ok := accounts[e.Acct]; ok {
+				expected[e.Acct] = acct.StartBalance
+			}
+		}
+		expected[e.Acct] += e.Signed()
+	}
+	for num, acct := range accounts {
+		bal, ok := expected[num]
+		if !ok {
+			bal = acct.StartBalance
+		}
+		if bal != acct.Checking {
+			report.BalancesOff++
+		}
+	}
+	return report
+}
+
+func (p *Posting) String() string {
+	return fmt.Sprintf("%s %s %s", p.Kind, p.Acct, fmtCents(p.Amount))
+}
@@ -0,0 +1,320 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/DrJosh9000/yarn"
+	"github.com/DrJosh9000/yarn/bytecode"
+	"golang.org/x/exp/maps"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// LintError is a mistake in Yarn content which would otherwise only turn up while playing.
+type LintError struct {
+	File string
+	Line int
+	Node string
+	Msg  string
+}
+
+func (e LintError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("%s: %s", e.Node, e.Msg)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", e.File, e.Line, e.Node, e.Msg)
+}
+
+// Lint checks a compiled program against the day schedule, the node headers the game understands, the commands it
+// runs and the resources it has, and checks translations against it. Errors are reported at the nearest line of
+// dialogue in the string table.
+func Lint(prog *bytecode.Program, st *yarn.StringTable) []LintError {
+	l := &linter{prog: prog, st: st, parts: Resources.parts, art: art}
+	l.days(Days())
+	names := maps.Keys(prog.Nodes)
+	sort.Strings(names)
+	for _, name := range names {
+		l.node(name, prog.Nodes[name])
+	}
+	l.locales()
+	sort.SliceStable(l.errs, func(i, j int) bool {
+		if l.errs[i].File != l.errs[j].File {
+			return l.errs[i].File < l.errs[j].File
+		}
+		return l.errs[i].Line < l.errs[j].Line
+	})
+	return l.errs
+}
+
+type linter struct {
+	prog  *bytecode.Program
+	st    *yarn.StringTable
+	parts PartsManifest
+	art   fs.FS
+	errs  []LintError
+}
+
+func (l *linter) errorf(at *yarn.StringTableRow, node, format string, args ...interface{}) {
+	e := LintError{Node: node, Msg: fmt.Sprintf(format, args...)}
+	if at != nil {
+		e.File, e.Line = at.File, at.LineNumber
+	}
+	l.errs = append(l.errs, e)
+}
+
+// days checks that every node named by the schedule exists.
+func (l *linter) days(days []*Day) {
+	missing := make(map[string][]int) // missing maps nodes which don't exist to the days they're scheduled.
+	var order []string
+	for i, day := range days {
+		names := append(append(day.Sequence[:len(day.Sequence):len(day.Sequence)], day.Random...), day.EndNode)
+		for _, name := range names {
+			if _, ok := l.prog.Nodes[name]; ok || name == "random" || name == "" {
+				continue
+			}
+			if len(missing[name]) == 0 {
+				order = append(order, name)
+			}
+			if !contains(missing[name], i+1) {
+				missing[name] = append(missing[name], i+1)
+			}
+		}
+	}
+	for _, name := range order {
+		l.errorf(nil, name, "node scheduled on days %v does not exist", missing[name])
+	}
+}
+
+// node checks the headers, line tags and commands of a node.
+func (l *linter) node(name string, node *bytecode.Node) {
+	first := l.firstLine(name)
+	portraitID := portrait(node)
+	for _, h := range node.Headers {
+		var p Presentation
+		if err := p.Apply(h.Key, h.Value); err != nil {
+			l.errorf(first, name, "%v", err)
+		}
+		if err := l.emotion(portraitID, p.Emotion); err != nil {
+			l.errorf(first, name, "%v", err)
+		}
+		switch h.Key {
+		case "intent":
+			if !validIntent(h.Value) {
+				l.errorf(first, name, "unknown intent %q", h.Value)
+			}
+		case "portrait":
+			if err := l.portrait(h.Value); err != nil {
+				l.errorf(first, name, "portrait %q: %v", h.Value, err)
+			}
+		}
+	}
+	var last *yarn.StringTableRow // last is the most recent line, which commands are reported against.
+	var pending []string          // pending are errors in commands which came before any line.
+	for _, inst := range node.Instructions {
+		switch inst.Opcode {
+		case bytecode.Instruction_RUN_LINE, bytecode.Instruction_ADD_OPTION:
+			last = l.st.Table[inst.Operands[0].GetStringValue()]
+			l.tags(name, portraitID, last)
+			l.markup(name, last)
+			for _, msg := range pending {
+				l.errorf(last, name, "%s", msg)
+			}
+			pending = nil
+		case bytecode.Instruction_RUN_COMMAND:
+			if err := l.command(inst.Operands[0].GetStringValue()); err != nil {
+				if last == nil {
+					pending = append(pending, err.Error())
+				} else {
+					l.errorf(last, name, "%v", err)
+				}
+			}
+		}
+	}
+	for _, msg := range pending {
+		l.errorf(first, name, "%s", msg)
+	}
+}
+
+// tags checks the presentation tags on a line.
+func (l *linter) tags(node, portraitID string, row *yarn.StringTableRow) {
+	if row == nil {
+		return
+	}
+	for _, tag := range row.Tags {
+		var p Presentation
+		if err := p.ApplyTag(tag); err != nil {
+			l.errorf(row, node, "%v", err)
+		}
+		if err := l.emotion(portraitID, p.Emotion); err != nil {
+			l.errorf(row, node, "%v", err)
+		}
+	}
+}
+
+// markup checks the markup attributes on a line. Substitutions are filled in with 1, which is enough to check pauses
+// and sizes which come from variables.
+func (l *linter) markup(node string, row *yarn.StringTableRow) {
+	if row == nil {
+		return
+	}
+	substs := make([]string, len(substitutionPattern.FindAllString(row.Text, -1)))
+	for i := range substs {
+		substs[i] = "1"
+	}
+	s, err := row.Render(substs, l.st.Language)
+	if err != nil {
+		l.errorf(row, node, "%v", err)
+		return
+	}
+	if _, err := parseMarkup(s); err != nil {
+		l.errorf(row, node, "%v", err)
+	}
+}
+
+// emotion checks that a portrait has a variant for the provided emotion. Random portraits can't be checked.
+func (l *linter) emotion(portraitID, emotion string) error {
+	if emotion == "" || emotion == "neutral" || portraitID == "" || strings.HasPrefix(portraitID, "random") {
+		return nil
+	}
+	head, _, _ := strings.Cut(portraitID, ":")
+	if err := l.image(emotionVariant(head, emotion)); err != nil {
+		return fmt.Errorf("no %s variant of %s: %v", emotion, head, err)
+	}
+	return nil
+}
+
+// firstLine finds the earliest line of the provided node in the string table.
+func (l *linter) firstLine(node string) *yarn.StringTableRow {
+	var result *yarn.StringTableRow
+	for _, row := range l.st.Table {
+		if row.Node == node && (result == nil || row.LineNumber < result.LineNumber) {
+			result = row
+		}
+	}
+	return result
+}
+
+func validIntent(intent string) bool {
+	switch intent {
+	case IntentCashCheck, IntentDeposit, IntentDepositCheck, IntentWithdraw, IntentRobbery:
+		return true
+	}
+	return false
+}
+
+// portrait checks a portrait header, which is interpreted the same way as in DialogueRunner.Customer.
+func (l *linter) portrait(id string) error {
+	toks := strings.Split(id, ":")
+	switch {
+	case id == "random":
+		return nil
+	case len(toks) == 1:
+		return l.image(toks[0])
+	case len(toks) != 2:
+		return fmt.Errorf("expected random, random:[tags], [image] or [head]:[body]")
+	case toks[0] == "random":
+		tags := strings.Split(toks[1], ",")
+		for _, category := range []string{PartHead, PartBody} {
+			if len(l.parts.filter(category, tags)) == 0 {
+				return fmt.Errorf("no %s in %s has all the tags %v", category, partsManifest, tags)
+			}
+		}
+		return nil
+	}
+	if err := l.image(toks[0]); err != nil {
+		return err
+	}
+	return l.image(toks[1])
+}
+
+func (l *linter) image(path string) error {
+	if _, err := fs.Stat(l.art, "gamedata/img/"+path); err != nil {
+		return fmt.Errorf("missing image %s", path)
+	}
+	return nil
+}
+
+// command checks a command and its arguments against the command registry.
+func (l *linter) command(cmd string) error {
+	c, args, err := parseCommand(cmd, true)
+	if err == nil && c.Name == "send_memo" && !Lang.Has(args[0].(string)) {
+		return fmt.Errorf("send_memo: %q isn't a key in ui.txt, so it can't be translated", args[0])
+	}
+	return err
+}
+
+var (
+	verbPattern         = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`)
+	substitutionPattern = regexp.MustCompile(`\{\d+\}`)
+)
+
+// locales checks each translation against the default locale. Every key and line translated must be one the game has,
+// and must fill in the same things.
+func (l *linter) locales() {
+	base, err := readCatalog(langFiles, path.Join(langDir, DefaultLocale, "ui.txt"))
+	if err != nil {
+		l.errs = append(l.errs, LintError{Node: DefaultLocale, Msg: err.Error()})
+		return
+	}
+	for _, code := range Locales() {
+		if code == DefaultLocale {
+			continue
+		}
+		if _, err := LoadLocale(code); err != nil {
+			l.errs = append(l.errs, LintError{Node: code, Msg: err.Error()})
+			continue
+		}
+		ui, _ := readCatalog(langFiles, path.Join(langDir, code, "ui.txt"))
+		keys := maps.Keys(ui)
+		sort.Strings(keys)
+		for _, key := range keys {
+			orig, ok := base[key]
+			switch {
+			case key == "locale.name":
+			case !ok:
+				l.errs = append(l.errs, LintError{Node: code + "/ui.txt", Msg: fmt.Sprintf("%s isn't in %s", key, DefaultLocale)})
+			case !sameMatches(verbPattern, orig, ui[key]):
+				l.errs = append(l.errs, LintError{Node: code + "/ui.txt", Msg: fmt.Sprintf("%s should fill in %v, like %s", key,
+					verbPattern.FindAllString(orig, -1), DefaultLocale)})
+			}
+		}
+		l.lines(code)
+	}
+}
+
+// lines checks the translated dialogue for a locale; errors are reported at the original line.
+func (l *linter) lines(code string) {
+	f, err := langFiles.Open(path.Join(langDir, code, "game-Lines.csv"))
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	st, err := yarn.ReadStringTable(f, code)
+	if err != nil {
+		l.errs = append(l.errs, LintError{Node: code + "/game-Lines.csv", Msg: err.Error()})
+		return
+	}
+	ids := maps.Keys(st.Table)
+	sort.Strings(ids)
+	for _, id := range ids {
+		orig, ok := l.st.Table[id]
+		switch {
+		case !ok:
+			l.errs = append(l.errs, LintError{Node: code + "/game-Lines.csv", Msg: fmt.Sprintf("no line %s in the game", id)})
+		case !sameMatches(substitutionPattern, orig.Text, st.Table[id].Text):
+			l.errorf(orig, orig.Node, "%s translation should fill in %v", code, substitutionPattern.FindAllString(orig.Text, -1))
+		default:
+			l.markup(orig.Node, st.Table[id])
+		}
+	}
+}
+
+// sameMatches reports whether the pattern matches the same things in both strings, in any order.
+func sameMatches(pattern *regexp.Regexp, a, b string) bool {
+	as, bs := pattern.FindAllString(a, -1), pattern.FindAllString(b, -1)
+	sort.Strings(as)
+	sort.Strings(bs)
+	return strings.Join(as, " ") == strings.Join(bs, " ")
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"github.com/DrJosh9000/yarn"
+	"github.com/Frabjous-Studios/bankwave/internal/yarnc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLinter_Portrait(t *testing.T) {
+	parts, err := parseManifest(strings.NewReader("head robo.png robot\nbody suit.png robot\nbody coat.png"))
+	assert.NoError(t, err)
+	l := &linter{parts: parts, art: fstest.MapFS{"gamedata/img/robo.png": {}, "gamedata/img/suit.png": {}}}
+
+	assert.NoError(t, l.portrait("random"))
+	assert.NoError(t, l.portrait("random:robot"))
+	assert.NoError(t, l.portrait("robo.png"))
+	assert.NoError(t, l.portrait("robo.png:suit.png"))
+
+	assert.Error(t, l.portrait("random:cat"))
+	assert.Error(t, l.portrait("robo.png:coat.png"))
+	assert.Error(t, l.portrait("a:b:c"))
+}
+
+const lintSource = `title: Start
+portrait: manager.png
+intent: haggle
+---
+<< put_cash lots >>
+Hello. #emotion:furious
+<< play_sound nope.ogg >>
+Bye.
+<< send_memo memo.nope >>
+===
+`
+
+func TestLint(t *testing.T) {
+	f, err := yarnc.Parse("test.yarn", lintSource)
+	require.NoError(t, err)
+	out, err := yarnc.Compile("test", []*yarnc.File{f})
+	require.NoError(t, err)
+	dir := t.TempDir()
+	require.NoError(t, out.WriteFiles(dir))
+	prog, st, err := yarn.LoadFiles(filepath.Join(dir, "test.yarnc"), "en-US")
+	require.NoError(t, err)
+
+	errs := Lint(prog, st)
+	for _, want := range []LintError{
+		{Node: "Manager_Day1", Msg: "node scheduled on days [1] does not exist"},
+		{File: "test.yarn", Line: 6, Node: "Start", Msg: `unknown intent "haggle"`},
+		{File: "test.yarn", Line: 6, Node: "Start", Msg: `put_cash: dollars must be a whole number; got "lots"`}, // before any line.
+		{File: "test.yarn", Line: 6, Node: "Start", Msg: "no furious variant of manager.png: missing image manager_furious.png"},
+		{File: "test.yarn", Line: 6, Node: "Start", Msg: "play_sound: missing sound file nope.ogg"},
+		{File: "test.yarn", Line: 8, Node: "Start", Msg: `send_memo: "memo.nope" isn't a key in ui.txt, so it can't be translated`},
+	} {
+		assert.Contains(t, errs, want)
+	}
+}
+
+func TestLint_Embedded(t *testing.T) {
+	prog, st, err := yarn.LoadFilesFS(yarnBin, yarnFile+".yarnc", DefaultLocale)
+	require.NoError(t, err)
+	assert.Empty(t, Lint(prog, st))
+}
@@ -0,0 +1,269 @@
+package internal
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"github.com/DrJosh9000/yarn"
+	"github.com/Frabjous-Studios/bankwave/internal/debug"
+	cldr "github.com/razor-1/localizer-cldr"
+	cldrdata "github.com/razor-1/localizer-cldr/resources"
+	"golang.org/x/text/language"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DefaultLocale is the language the game is written in. Anything missing from another locale is taken from here.
+const DefaultLocale = "en-US"
+
+// langFiles holds a directory for each locale, named for its code. Translators only touch these files:
+//
+//	ui.txt          key = text, one per line, for menus, the terminal and memos. Verbs like %s are filled in by the game.
+//	barks/*.txt     one bark per line; <name>_happy.txt and <name>_angry.txt are said by customers in those moods.
+//	report.tmpl     the reconciliation report, as a text/template.
+//	game-Lines.csv  the dialogue; a copy of gamedata/yarn/bin/game-Lines.csv with the text column translated.
+//
+//go:embed gamedata/lang
+var langFiles embed.FS
+
+const langDir = "gamedata/lang"
+
+// Lang is the locale the game is being played in.
+var Lang *Locale
+
+// Locale is a language the game can be played in, along with the way it writes numbers.
+type Locale struct {
+	Code string // Code is the locale's BCP 47 code, e.g. en-US.
+	Name string // Name is what the language calls itself, e.g. Español; ui.txt can override it with locale.name.
+
+	ui       map[string]string
+	barks    map[string][]string
+	number   cldr.Number
+	fallback *Locale
+}
+
+func init() {
+	var err error
+	Lang, err = LoadLocale(DefaultLocale)
+	if err != nil {
+		panic(fmt.Errorf("unable to load default locale: %w", err))
+	}
+}
+
+// Locales lists the codes of every locale the game can be played in.
+func Locales() []string {
+	entries, err := langFiles.ReadDir(langDir)
+	if err != nil {
+		debug.Printf("error listing locales: %v", err)
+	}
+	var result []string
+	for _, e := range entries {
+		if e.IsDir() {
+			result = append(result, e.Name())
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// SetLocale switches the game to the locale with the provided code.
+func SetLocale(code string) error {
+	l, err := LoadLocale(code)
+	if err != nil {
+		return err
+	}
+	Lang = l
+	return nil
+}
+
+// LoadLocale reads the locale with the provided code from gamedata/lang.
+func LoadLocale(code string) (*Locale, error) {
+	tag, err := language.Parse(code)
+	if err != nil {
+		return nil, fmt.Errorf("bad locale %q: %w", code, err)
+	}
+	dir := path.Join(langDir, code)
+	if _, err := fs.Stat(langFiles, dir); err != nil {
+		return nil, fmt.Errorf("no locale %q in %s", code, langDir)
+	}
+	data, err := cldrLocale(tag)
+	if err != nil {
+		return nil, fmt.Errorf("no CLDR data for %s: %w", code, err)
+	}
+	l := &Locale{Code: code, number: data.Number, barks: make(map[string][]string)}
+	base, _ := tag.Base()
+	l.Name = capitalize(data.Languages[base.String()])
+	if l.Name == "" {
+		l.Name = code
+	}
+	if l.ui, err = readCatalog(langFiles, path.Join(dir, "ui.txt")); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	if name, ok := l.ui["locale.name"]; ok {
+		l.Name = name
+	}
+	if code != DefaultLocale {
+		if l.fallback, err = LoadLocale(DefaultLocale); err != nil {
+			return nil, err
+		}
+	}
+	return l, nil
+}
+
+// cldrLocale finds CLDR data for the tag, or else for its language.
+func cldrLocale(tag language.Tag) (*cldr.Locale, error) {
+	if data, err := cldrdata.GetLocale(tag); err == nil {
+		return data, nil
+	}
+	base, _ := tag.Base()
+	return cldrdata.GetLocale(language.Make(base.String()))
+}
+
+// readCatalog reads a file of key = text lines. Blank lines and lines starting with # are skipped.
+func readCatalog(fsys fs.FS, name string) (map[string]string, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	result := make(map[string]string)
+	s := bufio.NewScanner(f)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, text, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected key = text", name, n)
+		}
+		result[strings.TrimSpace(key)] = strings.TrimSpace(text)
+	}
+	return result, s.Err()
+}
+
+// Has reports whether the provided key is in the UI catalog.
+func (l *Locale) Has(key string) bool {
+	if _, ok := l.ui[key]; ok {
+		return true
+	}
+	return l.fallback != nil && l.fallback.Has(key)
+}
+
+// T translates the provided key from the UI catalog, filling in any args. Missing keys come back as they are.
+func (l *Locale) T(key string, args ...any) string {
+	text, ok := l.ui[key]
+	switch {
+	case !ok && l.fallback != nil:
+		return l.fallback.T(key, args...)
+	case !ok:
+		debug.Printf("missing text for %s", key)
+		return key
+	case len(args) == 0:
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// T translates the provided key from the current locale's UI catalog.
+func T(key string, args ...any) string {
+	return Lang.T(key, args...)
+}
+
+// Barks reads the barks with the provided name; see utterances.go.
+func (l *Locale) Barks(name string) Barks {
+	return Barks{Happy: l.list(name + "_happy"), Neutral: l.list(name), Angry: l.list(name + "_angry")}
+}
+
+// list reads a list of barks, one per line, falling back to the default locale if the file is missing.
+func (l *Locale) list(name string) []string {
+	if lines, ok := l.barks[name]; ok {
+		return lines
+	}
+	var lines []string
+	f, err := langFiles.Open(path.Join(langDir, l.Code, "barks", name+".txt"))
+	if err == nil {
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			if line := strings.TrimSpace(s.Text()); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		_ = f.Close()
+	} else if l.fallback != nil {
+		lines = l.fallback.list(name)
+	}
+	l.barks[name] = lines
+	return lines
+}
+
+// Money formats an amount in cents the way the locale writes money, e.g. 1,234.50 or 1.234,50.
+func (l *Locale) Money(cents int) string {
+	n := l.number
+	pattern, _, _ := strings.Cut(n.Formats.Decimal, ";")
+	whole, _, _ := strings.Cut(pattern, ".")
+	n.Formats.Decimal = whole + ".00"
+	return n.FmtNumber(float64(cents) / 100)
+}
+
+// ParseMoney reads an amount typed by the player into cents. Either the locale's decimal separator or a point can be
+// used; group separators are ignored.
+func (l *Locale) ParseMoney(s string) (int, error) {
+	if sym := l.number.Symbols; sym.Decimal != "." && strings.Contains(s, sym.Decimal) {
+		s = strings.ReplaceAll(s, sym.Group, "")
+		s = strings.Replace(s, sym.Decimal, ".", 1)
+	} else if sym.Group != "." {
+		s = strings.ReplaceAll(s, sym.Group, "")
+	}
+	return parseCents(s)
+}
+
+// Report reads the template for the reconciliation report.
+func (l *Locale) Report() (*template.Template, error) {
+	b, err := fs.ReadFile(langFiles, path.Join(langDir, l.Code, "report.tmpl"))
+	if errors.Is(err, fs.ErrNotExist) && l.fallback != nil {
+		b, err = fs.ReadFile(langFiles, path.Join(langDir, DefaultLocale, "report.tmpl"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return template.New("report").Funcs(template.FuncMap{"fmtCents": l.Money}).Parse(string(b))
+}
+
+// Lines translates the text of a Yarn string table. Lines missing from the locale's game-Lines.csv are left alone.
+func (l *Locale) Lines(st *yarn.StringTable) error {
+	f, err := langFiles.Open(path.Join(langDir, l.Code, "game-Lines.csv"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	translated, err := yarn.ReadStringTable(f, l.Code)
+	if err != nil {
+		return fmt.Errorf("%s game-Lines.csv: %w", l.Code, err)
+	}
+	for id, row := range translated.Table {
+		if orig, ok := st.Table[id]; ok && row.Text != "" {
+			copied := *orig
+			copied.Text = row.Text
+			st.Table[id] = &copied
+		}
+	}
+	return nil
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
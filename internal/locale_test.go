@@ -0,0 +1,51 @@
+package internal
+
+import (
+	"github.com/DrJosh9000/yarn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestLocale_Money(t *testing.T) {
+	es, err := LoadLocale("es-ES")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "1,234.50", Lang.Money(123450))
+	assert.EqualValues(t, "1.234,50", es.Money(123450))
+	assert.EqualValues(t, "-0,05", es.Money(-5))
+
+	for s, want := range map[string]int{"12,50": 1250, "12.50": 1250, "1.234,5": 123450, "7": 700} {
+		got, err := es.ParseMoney(s)
+		assert.NoError(t, err)
+		assert.EqualValues(t, want, got, s)
+	}
+}
+
+func TestLocale_T(t *testing.T) {
+	es, err := LoadLocale("es-ES")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Idioma: Espanol", es.T("menu.language", es.Name))
+	assert.EqualValues(t, "POSTED DEP 12.00", Lang.T("terminal.posted", "DEP", Lang.Money(1200)))
+	assert.EqualValues(t, "no.such.key", es.T("no.such.key"))
+	assert.NotEmpty(t, es.Barks(TrashBarks).Neutral) // falls back to en-US.
+}
+
+func TestLocale_Lines(t *testing.T) {
+	es, err := LoadLocale("es-ES")
+	require.NoError(t, err)
+	st := &yarn.StringTable{Table: map[string]*yarn.StringTableRow{
+		"line:Drone.yarn-drone-0": {ID: "line:Drone.yarn-drone-0", Text: "Oh, hello.", Node: "drone", Tags: []string{"lastline"}},
+		"line:Nowhere-0":          {ID: "line:Nowhere-0", Text: "Not translated."},
+	}}
+	require.NoError(t, es.Lines(st))
+
+	row := st.Table["line:Drone.yarn-drone-0"]
+	assert.EqualValues(t, "Ah, hola.", row.Text)
+	assert.EqualValues(t, "line:Drone.yarn-drone-0", row.ID)
+	assert.EqualValues(t, "drone", row.Node)
+	assert.EqualValues(t, []string{"lastline"}, row.Tags)
+	assert.EqualValues(t, "Not translated.", st.Table["line:Nowhere-0"].Text)
+
+	assert.EqualValues(t, "Gracias!", es.Barks(ThanksBarks).Neutral[0])
+	assert.EqualValues(t, Lang.Barks(ThanksBarks).Happy, es.Barks(ThanksBarks).Happy, "falls back to en-US")
+}
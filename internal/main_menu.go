@@ -107,7 +107,7 @@ func (m *MainMenuScene) createMenuUI() (*ebitenui.UI, error) {
 	buttons := widget.NewContainer(
 		widget.ContainerOpts.Layout(widget.NewGridLayout(
 			widget.GridLayoutOpts.Columns(1),
-			widget.GridLayoutOpts.Stretch([]bool{false}, []bool{false, false, false, false}),
+			widget.GridLayoutOpts.Stretch([]bool{false}, []bool{false, false, false, false, false}),
 			widget.GridLayoutOpts.Padding(widget.Insets{Top: 20, Bottom: 20}),
 			widget.GridLayoutOpts.Spacing(0, 20),
 		)),
@@ -118,9 +118,10 @@ func (m *MainMenuScene) createMenuUI() (*ebitenui.UI, error) {
 	)
 
 	m.buttons = []*widget.Button{
-		m.button("New Game", newGame),
-		m.button("Credits", showCredits),
-		m.button("Exit", exitGame),
+		m.button(T("menu.new_game"), newGame),
+		m.button(T("menu.language", Lang.Name), m.nextLanguage),
+		m.button(T("menu.credits"), showCredits),
+		m.button(T("menu.exit"), exitGame),
 	}
 	for _, b := range m.buttons {
 		buttons.AddChild(b)
@@ -133,6 +134,25 @@ func (m *MainMenuScene) createMenuUI() (*ebitenui.UI, error) {
 	}, nil
 }
 
+// nextLanguage switches to the next locale, saves the choice, and relabels the menu.
+func (m *MainMenuScene) nextLanguage(g *Game) {
+	locales := Locales()
+	next := locales[0]
+	for i, code := range locales {
+		if code == Lang.Code && i+1 < len(locales) {
+			next = locales[i+1]
+		}
+	}
+	debug.Println("switching language to", next)
+	g.Settings.Locale = next
+	g.Settings.Apply()
+	if err := g.Settings.Save(); err != nil {
+		debug.Printf("error saving settings: %v", err)
+	}
+	m.ui, _ = m.createMenuUI()
+	m.updateButtons()
+}
+
 func newGame(g *Game) {
 	debug.Println("New game clicked")
 	g.ChangeScene(NewMainScene(g))
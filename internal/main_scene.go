@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"github.com/DrJosh9000/yarn"
 	"github.com/Frabjous-Studios/bankwave/internal/debug"
@@ -53,6 +54,22 @@ const (
 	StateFadingToNewDay
 )
 
+func (s SceneState) String() string {
+	switch s {
+	case StateFadeIn:
+		return "fading in"
+	case StateApproaching:
+		return "approaching"
+	case StateConversing:
+		return "conversing"
+	case StateDismissing:
+		return "dismissing"
+	case StateReporting:
+		return "reporting"
+	}
+	return "fading to new day"
+}
+
 var startTime time.Time
 
 type MainScene struct {
@@ -74,17 +91,33 @@ type MainScene struct {
 	till         *Till
 	counter      *BaseSprite
 	terminal     *Terminal
+	console      *Console // console is the developer console.
+	backlog      *Backlog // backlog is the history of what's been said.
 	buttonBase   *BaseSprite
 	buttonHolo   *Hologram
 	shredder     *Shredder
 	trashChute   *TrashChute
 	alarmButtons *AlarmButtons
 	silhouettes  *Silhouettes
+	queue        *Queue
+
+	robbery   *Robbery // robbery is the robbery in progress, if any.
+	policeIn  int      // policeIn is the number of customers until the police arrive; 0 if they haven't been called.
+	policeFor *Robbery // policeFor is the robbery the police are responding to; nil for a false alarm.
+
+	regulars []*Regular // regulars are the customers who keep coming back.
+
+	walk *Walk // walk is the current customer's walk to or from the window, if they're walking.
+
+	served int // served is the number of customers served, over every day so far.
 
 	offscreen *ebiten.Image
 
-	bubbles *Bubbles
-	options []*Line
+	bubbles     *Bubbles
+	options     []*Line
+	optionIdx   int         // optionIdx is the option highlighted from the keyboard or a gamepad, or -1 for none.
+	lastCursor  image.Point // lastCursor is where the cursor was last frame; moving it highlights options instead.
+	fastForward bool        // fastForward skips lines the player has already seen.
 
 	holding     []Sprite
 	clickStart  image.Point
@@ -108,7 +141,7 @@ type MainScene struct {
 
 	black *ebiten.Image
 
-	dialogueLines   chan string
+	dialogueLines   chan dialogueLine
 	dialogueOptions chan int
 	lineShown       chan struct{}
 }
@@ -123,6 +156,7 @@ func NewMainScene(g *Game) *MainScene {
 		Sprites:          []Sprite{},
 		Days:             Days(),
 		State:            StateFadeIn,
+		optionIdx:        -1,
 		dayFadeStartTime: time.Now(),
 		till:             NewTill(),
 		portraitImg:      ebiten.NewImage(100, 100),
@@ -138,20 +172,23 @@ func NewMainScene(g *Game) *MainScene {
 		black:           placeholder(colornames.Black, 1, 1),
 		shredder:        NewShredder(),
 		silhouettes:     NewSilhouettes(),
+		queue:           NewQueue(),
+		regulars:        newRoster(),
 		trashChute:      NewTrashChute(),
 		alarmButtons:    NewAlarmButtons(g.ACtx),
-		dialogueLines:   make(chan string),
+		dialogueLines:   make(chan dialogueLine),
 		dialogueOptions: make(chan int),
 		lineShown:       make(chan struct{}),
 		dayNight:        Resources.GetShader("day_night"),
 	}
 	result.Day = result.Days[0]
+	result.regularAccounts(result.Day)
 	result.randomizeTill()
 
 	result.bubbles = NewBubbles(result)
 	result.endOfDaySync = sync.NewCond(&result.mut)
 
-	result.Runner, err = NewDialogueRunner(result.vars, result)
+	result.Runner, err = NewDialogueRunner(result.vars, result, result.yarnFuncs())
 
 	result.txt = etxt.NewStdRenderer()
 	result.txt.SetCacheHandler(etxt.NewDefaultCache(4 * 1024 * 1024).NewHandler())
@@ -161,6 +198,8 @@ func NewMainScene(g *Game) *MainScene {
 	result.txt.SetSizePx(6)
 
 	result.terminal = NewTerminal(result.txt, result)
+	result.console = NewConsole(result.txt, result)
+	result.backlog = NewBacklog(result.txt, result)
 
 	result.startDialogueReceivers()
 
@@ -172,15 +211,39 @@ func NewMainScene(g *Game) *MainScene {
 	return result
 }
 
-const DismissalPxPerSecond = 100
-
 const DayFadeTime = 1 * time.Second
 
+// dialogueLine is a rendered line of dialogue, and how to present it.
+type dialogueLine struct {
+	Text string
+	Presentation
+}
+
+// present shows a line of dialogue, speaking for and swapping the expression of the customer as directed.
+func (m *MainScene) present(line dialogueLine) {
+	if c := m.Customer; c != nil {
+		if line.Speaker == "customer" {
+			line.Speaker = c.CustomerName
+		}
+		c.QueueEmotion(line.Emotion)
+	}
+	m.backlog.Say(line.Speaker, line.Text)
+	m.bubbles.Present(line.Text, line.Presentation)
+}
+
+// bark has the customer say something outside of Yarn.
+func (m *MainScene) bark(text string) {
+	if m.Customer != nil {
+		m.backlog.Say(m.Customer.CustomerName, text)
+	}
+	m.bubbles.SetLine(text)
+}
+
 func (m *MainScene) startDialogueReceivers() {
 	go func() {
 		for line := range m.dialogueLines {
-			debug.Printf("received dialogue line: %v\n", line)
-			m.bubbles.SetLine(line)
+			debug.Printf("received dialogue line: %v\n", line.Text)
+			m.present(line)
 			// don't receive another line until this one has been totally shown.
 			<-m.lineShown
 		} // TODO: shut down
@@ -192,14 +255,14 @@ func (m *MainScene) Update() error {
 
 	if m.State == StateFadingToNewDay {
 		if time.Now().Sub(m.dayFadeStartTime) > DayFadeTime {
-			m.State = StateFadeIn
+			m.setState(StateFadeIn)
 			m.endOfDaySync.Broadcast()
 			m.dayFadeStartTime = time.Now()
 		}
 		return nil
 	} else if m.State == StateFadeIn {
 		if time.Now().Sub(m.dayFadeStartTime) > DayFadeTime {
-			m.State = StateApproaching
+			m.setState(StateApproaching)
 			m.endOfDaySync.Broadcast()
 			m.dayFadeStartTime = time.Time{}
 		}
@@ -209,40 +272,59 @@ func (m *MainScene) Update() error {
 	}
 	m.bubbles.Update()
 	m.terminal.Update()
+	m.console.Update()
+	m.backlog.Update()
+	for _, memo := range m.Day.DueMemos(m.dayLength()) {
+		m.terminal.Deliver(memo)
+	}
+	m.updateQueue()
+	m.updatePatience()
+	if m.Customer != nil {
+		m.Customer.UpdateEmotion()
+	}
 
 	switch m.State {
 	case StateApproaching:
-		// TODO: animate the customer approaching
-		if !m.Runner.running {
-			m.startRunner()
-			m.Runner.running = true
+		if m.walk == nil && !m.Runner.running {
+			m.callCustomer()
 		}
-		if m.Customer != nil {
+		if m.walk != nil && m.walk.Step(m.Customer) { // don't start talking until they're at the window.
+			m.walk = nil
 			debug.Println("transition to conversing")
-			m.State = StateConversing
+			m.setState(StateConversing) // before the runner starts, so the node's first commands can run.
+			m.startRunner()
 		}
 	case StateDismissing:
-
-		m.Customer.MoveX(DismissalPxPerSecond / TPS)
-		if m.Customer.Pos().X > m.Game.Width/2 {
+		if m.Customer == nil {
+			m.setState(StateApproaching)
+			break
+		}
+		if m.walk == nil || !m.walk.Leaving {
+			m.abandonRobbery() // commands don't run while dismissing, so resolve_robbery never will.
+			m.recordVisit()
+			m.countServed()
+			m.walk = newWalk(m.Customer, true)
+		}
+		if m.walk.Step(m.Customer) {
 			debug.Println("transition to approaching")
+			m.walk = nil
 			m.clearCustomer()
-			m.State = StateApproaching
+			m.setState(StateApproaching)
 		}
 	}
 	m.maybeHoverDrone()
 
 	cPos := cursorPos()
-	for _, opt := range m.options {
+	for idx, opt := range m.options {
 		if opt == nil {
 			continue
 		}
-		if cPos.Mul(ScaleFactor).In(opt.Rect) {
-			opt.highlighted = true
-		} else {
-			opt.highlighted = false
+		if cPos != m.lastCursor && cPos.Mul(ScaleFactor).In(opt.Rect) {
+			m.optionIdx = idx
 		}
+		opt.highlighted = idx == m.optionIdx
 	}
+	m.lastCursor = cPos
 
 	if m.holding != nil {
 		mPos := cursorPos()
@@ -254,6 +336,15 @@ func (m *MainScene) Update() error {
 	return nil
 }
 
+// updateQueue sizes the line in the lobby from the day's schedule; the manager complains when it gets too long.
+func (m *MainScene) updateQueue() {
+	m.queue.Resize(m.Day.Waiting(m.dayLength()))
+	if m.queue.Length >= LongLineLength && !m.Day.LongLineWarned {
+		m.Day.LongLineWarned = true
+		m.terminal.Deliver(&Memo{From: T("memo.from_manager"), Text: T("memo.long_line")})
+	}
+}
+
 func (m *MainScene) clearCustomer() {
 	m.Runner.mut.Lock()
 	defer m.Runner.mut.Unlock()
@@ -303,10 +394,10 @@ func (m *MainScene) updateInput() error {
 	heldKeys = inpututil.AppendPressedKeys(heldKeys[:0])
 
 	if m.State == StateReporting {
-		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) || confirmPressed() {
 			m.reportDismissed = true
 			if m.reportDismissed {
-				m.State = StateConversing
+				m.setState(StateConversing)
 			}
 			m.bubbles.TextBounds = DialogueBounds
 			m.bubbles.SetLine("")
@@ -315,11 +406,15 @@ func (m *MainScene) updateInput() error {
 		return nil
 	}
 
-	if time.Now().Before(m.debouceTime) {
+	if time.Now().Before(m.debouceTime) || m.backlog.Open {
 		return nil
 	}
+	m.updateDialogueInput()
 
 	cPos := cursorPos()
+	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonLeft) {
+		m.terminal.Focus(len(m.holding) == 0 && cPos.In(m.terminal.Bounds()) && !m.console.Open)
+	}
 	if inpututil.IsMouseButtonJustPressed(ebiten.MouseButtonRight) {
 		debug.Println("right mouse press", m.holding)
 		if len(m.holding) > 0 {
@@ -375,8 +470,10 @@ func (m *MainScene) updateInput() error {
 				m.shredder.toggle()
 			} else if cPos.In(AlarmButtonLeft) {
 				m.alarmButtons.Press(AlarmModeLeft)
+				m.soundAlarm(AlarmModeLeft)
 			} else if cPos.In(AlarmButtonRight) {
 				m.alarmButtons.Press(AlarmModeRight)
+				m.soundAlarm(AlarmModeRight)
 			} else {
 				grabbed := m.spriteUnderCursor()
 				if grabbed != nil {
@@ -393,8 +490,7 @@ func (m *MainScene) updateInput() error {
 					for idx, opt := range m.options {
 						if cPos.Mul(ScaleFactor).In(opt.Rect) {
 							debug.Println("player selected dialog option; sending")
-							m.dialogueOptions <- idx
-							debug.Println("player dialogue option was sent")
+							m.chooseOption(idx)
 
 							selected = true
 							break
@@ -412,8 +508,13 @@ func (m *MainScene) updateInput() error {
 	return nil
 }
 
+// CapturingText is true while the player is typing into the terminal or the console.
+func (m *MainScene) CapturingText() bool {
+	return m.terminal.Focused || m.console.Open
+}
+
 func (m *MainScene) depart() error {
-	m.State = StateDismissing // without playing a sound
+	m.setState(StateDismissing) // without playing a sound
 	m.resetDialogue()
 	return nil
 }
@@ -423,13 +524,33 @@ func (m *MainScene) nextButton() {
 	s.Rewind()
 	s.Play()
 	if m.Customer != nil && m.Customer.ImageKey == "manager.png" {
-		m.bubbles.SetLine(randSlice(BossDismissal))
+		m.bark(Lang.Barks(BossDismissal).For(MoodNeutral, false))
 	} else {
-		m.State = StateDismissing
+		m.warnUnposted()
+		if m.State == StateConversing && m.Customer != nil && !m.Customer.Refused && strings.HasPrefix(m.CurrNode, "Random") {
+			m.changeMood(m.Customer.serviceMood(), ThanksBarks)
+		}
+		m.setState(StateDismissing)
 		m.resetDialogue()
 	}
 }
 
+// warnUnposted buzzes and lists any transactions for this customer the teller forgot to post on the terminal.
+func (m *MainScene) warnUnposted() {
+	unposted := m.Day.Ledger.UnpostedThisVisit()
+	if len(unposted) == 0 || !m.terminal.Operational {
+		return
+	}
+	snd := Resources.GetSound(m.Game.ACtx, "Buzzer-1.ogg")
+	snd.Rewind()
+	snd.Play()
+	m.terminal.lines = nil
+	m.terminal.Print(T("terminal.unposted"))
+	for _, p := range unposted {
+		m.terminal.Print(p.String())
+	}
+}
+
 // cheatValue is some random value added to required withdrawal thresholds for the customer to walk away on their own.
 // This keeps the player from letting the customer do their own counting.
 func cheatValue() int {
@@ -447,6 +568,10 @@ func (m *MainScene) customerDrop() {
 		return
 	}
 	debug.Println("dropping on customer!")
+	if m.robbery != nil {
+		m.handToRobber()
+		return
+	}
 	if m.Customer != nil {
 		if _, ok := m.holding[0].(*Money); ok {
 			totalValue := 0 // figure out the value of this fist full o' cash.
@@ -456,15 +581,15 @@ func (m *MainScene) customerDrop() {
 			// giving the customer money
 			if m.Customer.CustomerIntent == IntentDeposit {
 				m.Customer.CashOnCounter -= totalValue
-				if m.Customer.DepositSlip != nil && m.Customer.DepositSlip.Value > m.Customer.CashOnCounter { // put cash back to even out deposit
-					m.bubbles.SetLine(randSlice(CashBackDeposit))
-					diff := m.Customer.DepositSlip.Value - m.Customer.CashOnCounter
+				if m.Customer.DepositSlip != nil && m.Customer.DepositSlip.CashTotal() > m.Customer.CashOnCounter { // put cash back to even out deposit
+					m.changeMood(-1, CashBackBarks)
+					diff := m.Customer.DepositSlip.CashTotal() - m.Customer.CashOnCounter
 					m.putCashAndCoinsf(float32(diff) / 100) // make other money out of thin air; I'm trying to deposit; dammit. I won't leave until I do!
 				}
 			} else if m.Customer.CustomerIntent == IntentWithdraw {
 				m.Customer.CashInHand += totalValue
 				if m.Customer.DepositSlip != nil && m.Customer.CashInHand+cheatValue() >= m.Customer.DepositSlip.Value {
-					m.bubbles.SetLine("Thank you!")
+					m.changeMood(m.Customer.serviceMood(), ThanksBarks)
 					m.depart()
 				}
 			} // TODO: other intents
@@ -474,9 +599,10 @@ func (m *MainScene) customerDrop() {
 			}
 			m.holding = nil
 		} else if slip, ok := m.holding[0].(*DepositSlip); ok {
-			m.bubbles.SetLine(randSlice(WrongSlip))
+			m.changeMood(-1, WrongSlipBarks)
 			m.removeSprite(m.holding[0])
 			m.ReturnedSlips = append(m.ReturnedSlips, slip) // we'll check these at the end of the day.
+			m.Customer.Refused = true
 			m.holding = nil
 			// giving back their deposit slip.
 		} else if _, ok := m.holding[0].(*Stack); ok {
@@ -488,13 +614,14 @@ func (m *MainScene) customerDrop() {
 				m.holding[0].SetPos(randRudeCounterPos())
 			} else {
 				// you're giving away a stack of money?!!?! Yes please!
-				m.bubbles.SetLine(randSlice(FreeMoney))
+				m.changeMood(2, FreeMoneyBarks)
+				m.Customer.Overpaid = true
 				m.removeSprite(m.holding[0])
 				m.holding = nil
 				m.depart()
 			}
 		} else if _, ok := m.holding[0].(*Trash); ok {
-			m.bubbles.SetLine(randSlice(HandsTrash))
+			m.changeMood(-1, TrashBarks)
 		}
 
 	}
@@ -503,6 +630,7 @@ func (m *MainScene) customerDrop() {
 func (m *MainScene) trashDrop(sprites []Sprite) {
 	m.trashChute.Contents = append(m.trashChute.Contents, sprites...)
 	for _, sprite := range sprites {
+		m.Day.Disposals.Record(sprite, false)
 		m.removeSprite(sprite)
 	}
 	m.holding = nil
@@ -511,12 +639,17 @@ func (m *MainScene) trashDrop(sprites []Sprite) {
 func (m *MainScene) shredderDrop() {
 	switch m.shredder.Mode {
 	case ModeShred:
+		m.Day.Disposals.Record(m.holding[0], true)
 		m.removeSprite(m.holding[0]) // goodbye whatever you were!
 		m.holding = m.holding[1:]
+		m.shredSound()
 
 	case ModeScan:
-		if check, ok := m.holding[0].(*Check); ok {
-			m.terminal.ValidateCheck(check)
+		if m.terminal.Scan(m.holding[0]) {
+			m.dayStartTime = m.dayStartTime.Add(-ScanTimeCost) // scanning isn't free!
+			snd := Resources.GetSound(m.Game.ACtx, "Computer_Beep_Short-2.ogg")
+			snd.Rewind()
+			snd.Play()
 		}
 	}
 }
@@ -529,16 +662,20 @@ func (m *MainScene) shredSound() {
 
 func (m *MainScene) tillDrop() {
 	if m.till.DropAll(m.holding) {
-		if _, ok := m.holding[0].(*DepositSlip); ok {
+		if slip, ok := m.holding[0].(*DepositSlip); ok {
 			m.removeSprite(m.holding[0])
+			m.Day.AcceptSlip(slip)
 			m.playPaperPlace()
 		}
 		if _, ok := m.holding[0].(*Stack); ok {
 			m.removeSprite(m.holding[0])
 			m.playCashFlip()
 		}
-		if _, ok := m.holding[0].(*Check); ok {
+		if check, ok := m.holding[0].(*Check); ok {
 			m.removeSprite(m.holding[0])
+			if m.Customer != nil && m.Customer.CustomerIntent == IntentCashCheck {
+				m.Day.Ledger.ExpectCheck(m.customerAccount(), check)
+			}
 			m.playPaperPlace()
 		}
 		m.holding = nil
@@ -627,6 +764,7 @@ func (m *MainScene) BringToFront(s Sprite) {
 func (m *MainScene) Draw(screen *ebiten.Image) {
 	m.offscreen.Clear()
 	m.drawBg(m.offscreen)
+	m.queue.DrawTo(m.offscreen)
 	m.till.DrawTo(m.offscreen)
 
 	if m.Customer != nil {
@@ -677,6 +815,8 @@ func (m *MainScene) Draw(screen *ebiten.Image) {
 		dt := float32(time.Now().Sub(m.dayFadeStartTime).Seconds()) / float32(DayFadeTime.Seconds())
 		m.DrawFade(screen, 1-dt)
 	}
+	m.backlog.DrawTo(screen)
+	m.console.DrawTo(screen)
 }
 
 var unif map[string]any
@@ -742,7 +882,7 @@ func (m *MainScene) drawCashIndicator(screen *ebiten.Image) {
 	v, h := m.txt.GetAlign()
 	m.txt.SetAlign(etxt.YCenter, etxt.XCenter)
 	if isCoin {
-		m.txt.Draw(fmt.Sprintf("$%.02f", float32(fracVal)/100), ScaleFactor*cPos.X, ScaleFactor*cPos.Y+IndicatorOffset)
+		m.txt.Draw("$"+fmtCents(fracVal), ScaleFactor*cPos.X, ScaleFactor*cPos.Y+IndicatorOffset)
 	} else {
 		m.txt.Draw(fmt.Sprintf("$%d", value), ScaleFactor*cPos.X, ScaleFactor*cPos.Y+IndicatorOffset)
 	}
@@ -791,10 +931,23 @@ func (m *MainScene) dayLength() time.Duration {
 	return time.Now().Sub(m.dayStartTime)
 }
 
-func (m *MainScene) startRunner() {
-	debug.Println("starting runner!")
+// callCustomer calls the next customer up to the window.
+func (m *MainScene) callCustomer() {
+	debug.Println("calling customer!")
 	m.advanceCurrNode()
+	m.policeTick()
 	m.Customer = m.Runner.Customer(m.CurrNode)
+	m.maybeRegular()
+	m.Day.Ledger.StartVisit()
+	m.backlog.StartVisit(m.dayIdx+1, m.Customer.CustomerName, m.CurrNode)
+	m.setMoodVars()
+	m.walk = newWalk(m.Customer, false)
+}
+
+func (m *MainScene) startRunner() {
+	debug.Println("starting runner!")
+	m.bindVars()
+	m.Runner.running = true
 	go func() {
 		if err := m.Runner.DoNode(m.CurrNode); err != nil {
 			debug.Printf("error starting runner: %v", err)
@@ -875,47 +1028,55 @@ func (m *MainScene) isHeld(sprite Sprite) bool {
 
 func (m *MainScene) NodeStart(name string) error {
 	debug.Println("start node", name)
-	if m.State == StateDismissing {
+	m.Runner.CurrNodeName = name // jumps start new nodes too.
+	if m.state() == StateDismissing {
 		return yarn.Stop
 	}
 	return nil
 }
 
 func (m *MainScene) PrepareForLines(lineIDs []string) error {
-	if m.State == StateDismissing {
+	if m.state() == StateDismissing {
 		return yarn.Stop
 	}
 	return nil
 }
 
 func (m *MainScene) Line(line yarn.Line) error {
-	rendered := m.Runner.Render(line)
+	text, markup := m.Runner.Render(line)
+	rendered := dialogueLine{Text: text, Presentation: m.Runner.Presentation(line)}
+	rendered.Markup = markup
+	rendered.Seen = m.Game.sawLine(line.ID)
 	debug.Println("Line(): waiting to send a rendered dialogue line")
 	m.dialogueLines <- rendered
 	debug.Println("Line(): dialogue line sent")
 
-	if m.State == StateDismissing {
+	if m.state() == StateDismissing {
 		return yarn.Stop
 	}
 	return nil
 }
 
 func (m *MainScene) Options(options []yarn.Option) (int, error) {
-	m.mut.Lock()
-	defer m.mut.Unlock()
-	m.options = make([]*Line, 0, len(options))
+	chosen := make([]*Line, 0, len(options))
 	for _, opt := range options {
-		m.options = append(m.options, NewOption(m.Runner.Render(opt.Line)))
+		chosen = append(chosen, NewOption(m.Runner.Render(opt.Line)))
 	}
+	m.mut.Lock()
+	m.options, m.optionIdx = chosen, -1
+	m.mut.Unlock()
 	debug.Println("Options(): waiting for player to select an option")
 	opt := <-m.dialogueOptions
+	m.mut.Lock()
 	m.options = nil
+	m.mut.Unlock()
 	if opt == StopOption {
 		debug.Println("Options(): received stop option")
 		return 0, yarn.Stop
 	}
+	m.backlog.Choose(chosen[opt].Text)
 	debug.Println("Options() continuing, option selected:", opt)
-	if m.State == StateDismissing {
+	if m.state() == StateDismissing {
 		return 0, yarn.Stop
 	}
 	return opt, nil
@@ -923,7 +1084,7 @@ func (m *MainScene) Options(options []yarn.Option) (int, error) {
 
 func (m *MainScene) NodeComplete(nodeName string) error {
 	debug.Println("node done", nodeName)
-	if m.State == StateDismissing {
+	if m.state() == StateDismissing {
 		return yarn.Stop
 	}
 	return nil
@@ -931,50 +1092,53 @@ func (m *MainScene) NodeComplete(nodeName string) error {
 
 func (m *MainScene) DialogueComplete() error {
 	debug.Println("dialogue complete")
-	if m.State == StateDismissing {
+	if m.state() == StateDismissing {
 		return yarn.Stop
 	}
 	return nil
 }
 
+// Command runs a command from Yarn. Mistakes in commands are logged and skipped, rather than stopping the dialogue.
 func (m *MainScene) Command(command string) error {
 	debug.Println("run command:", command)
-	command = strings.TrimSpace(command)
-	tokens := strings.Split(command, " ")
-	if len(tokens) == 0 {
-		return fmt.Errorf("bad command: %s", command)
-	}
-	switch tokens[0] {
-	case "put_counter":
-		return m.putCounter(tokens[1:])
-	case "put_cash":
-		return m.putCash(tokens[1:])
-	case "put_coins":
-		return m.putCoinsCmd(tokens[1:])
-	case "put_cash_and_coins":
-		return m.putCashAndCoins(tokens[1:])
-	case "play_sound":
-		return m.playSound(tokens[1:])
-	case "depart":
-		return m.depart()
-	case "set_wrong":
-		return m.setWrong()
-	case "show_reconciliation_report":
-		return m.showReconciliationReport()
-	case "next_day":
-		return m.nextDay()
-	case "terminal_on":
-		m.terminal.Operational = true
-		return nil
-	case "terminal_off":
-		m.terminal.Operational = false
+	err := m.runCommand(command)
+	if err != nil && !errors.Is(err, yarn.Stop) {
+		debug.Printf("error running command: %v", err)
 		return nil
-	case "shredder_on":
-		m.shredder.enable()
-		return nil
-	default:
-		return fmt.Errorf("unknown command %s", tokens[0])
 	}
+	return err
+}
+
+// runCommand parses and runs a command from the registry, if it can run in the current state.
+func (m *MainScene) runCommand(line string) error {
+	cmd, args, err := parseCommand(line, false)
+	if err != nil && !errors.Is(err, errSkipped) {
+		return err
+	}
+	if state := m.state(); !contains(cmd.States, state) {
+		if state == StateDismissing {
+			return yarn.Stop
+		}
+		return fmt.Errorf("%s can't run while %s", cmd.Name, state)
+	}
+	if runErr := cmd.Run(m, args); runErr != nil {
+		return runErr
+	}
+	return err // reports any arguments which were skipped.
+}
+
+// state reads the scene's state; it's safe to call from the dialogue runner.
+func (m *MainScene) state() SceneState {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	return m.State
+}
+
+// setState changes the scene's state; it's safe to call from the dialogue runner.
+func (m *MainScene) setState(state SceneState) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+	m.State = state
 }
 
 func (m *MainScene) nextDay() error {
@@ -986,7 +1150,13 @@ func (m *MainScene) nextDay() error {
 	debug.Println("nextDay waiting for endOfDaySync")
 	m.endOfDaySync.Wait()
 	debug.Println("nextDay continuing")
+	m.saveRegularBalances()
 	m.randomizeTill() // a whooole new tiiiill!
+	m.policeIn, m.policeFor = 0, nil
+	m.queue.Reset()
+	m.Runner.SetVar(VarFalseAlarms, float32(0))
+	m.Runner.SetVar(VarStormOffs, float32(0))
+	m.Runner.SetVar(VarRobbersCaught, float32(0))
 	m.dayIdx++
 	if m.dayIdx == 4 {
 		m.Game.PlayMusic("ElectronicDraft2.ogg")
@@ -994,6 +1164,7 @@ func (m *MainScene) nextDay() error {
 	m.dayStartTime = time.Now()
 	if m.dayIdx < len(m.Days) {
 		m.Day = m.Days[m.dayIdx]
+		m.regularAccounts(m.Day)
 	} else {
 		// TODO: thanks for playing! Credits
 		mainMenu, _ := NewCreditsScene(m.Game)
@@ -1030,6 +1201,16 @@ func (m *MainScene) showReconciliationReport() error {
 	defer m.mut.Unlock()
 
 	m.report = m.till.Reconcile()
+	m.report.Ledger = m.Day.Ledger.Reconcile(m.Day.Accounts)
+	m.report.Disposals = m.Day.Disposals
+	for _, r := range m.Day.Robberies {
+		m.report.Robbed += r.Taken()
+	}
+	m.report.FalseAlarms = m.Day.FalseAlarms
+	m.report.LongestLine = m.queue.Longest
+	m.report.StormOffs = len(m.Day.StormOffs)
+	m.Runner.SetVar(VarLongestLine, float32(m.queue.Longest))
+	m.Runner.SetDisposals(&m.Day.Disposals)
 	m.bubbles.TextBounds = ReportBounds
 	m.bubbles.SetLine(m.report.String())
 	m.State = StateReporting
@@ -1039,59 +1220,50 @@ func (m *MainScene) showReconciliationReport() error {
 	return nil
 }
 
+// sendMemo delivers the rest of the command to the terminal inbox as a memo from the manager. If the text is a key in
+// the UI catalog, the memo is translated.
+func (m *MainScene) sendMemo(text string) error {
+	if Lang.Has(text) {
+		text = T(text)
+	}
+	m.terminal.Deliver(&Memo{From: T("memo.from_manager"), Text: text})
+	return nil
+}
+
 func (m *MainScene) setWrong() error {
 	if m.Customer == nil {
 		debug.Println("set_wrong called with nil customer")
 		return nil
 	}
-	if m.Customer.DepositSlip != nil {
-		m.Customer.DepositSlip.IsWrong = true
+	if slip := m.Customer.DepositSlip; slip != nil && slip.Error == SlipErrorNone {
+		slip.applyError(slip.randomError())
+		m.drawSlip(slip)
 	}
 	// TODO: set for checks and such as well.
 	return nil
 }
 
-func (m *MainScene) putCoinsCmd(args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf("call to put_coins bad arguments: %v", args)
-	}
-	amt, err := strconv.Atoi(args[0])
-	if err != nil {
-		return fmt.Errorf("call to put_coins wasn't integer: %v", err)
-	}
-	if amt < 0 {
-		return fmt.Errorf("amount passed to put_coins was negative: %v", err)
-	}
+func (m *MainScene) putCoinsCmd(amt int) error {
 	m.putCoins(amt)
-	if m.State == StateDismissing {
+	if m.state() == StateDismissing {
 		return yarn.Stop
 	}
 	return nil
 }
 
-func (m *MainScene) playSound(args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf("call to play_sound had bad number of arguments: %v", args)
-	}
-	player := Resources.GetSound(m.Game.ACtx, args[0])
+func (m *MainScene) playSound(file string) error {
+	player := Resources.GetSound(m.Game.ACtx, file)
 	if player == nil {
-		return fmt.Errorf("call to play_sound with missing sound file: %v", args[0])
+		return fmt.Errorf("call to play_sound with missing sound file: %v", file)
 	}
 	player.Rewind()
 	player.Play()
 	return nil
 }
 
-func (m *MainScene) putCashAndCoins(args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf("call to put_cash_and_coins bad arguments: %v", args)
-	}
-	val, err := strconv.ParseFloat(args[0], 32)
-	if err != nil {
-		return fmt.Errorf("call to put_cash_and_coins wasn't integer: %v", err)
-	}
-	m.putCashAndCoinsf(float32(val))
-	if m.State == StateDismissing {
+func (m *MainScene) putCashAndCoins(val float32) error {
+	m.putCashAndCoinsf(val)
+	if m.state() == StateDismissing {
 		return yarn.Stop
 	}
 	return nil
@@ -1106,19 +1278,9 @@ func (m *MainScene) putCashAndCoinsf(val float32) {
 	m.putCoins(coin)
 }
 
-func (m *MainScene) putCash(args []string) error {
-	if len(args) != 1 {
-		return fmt.Errorf("call to put_cash bad arguments: %v", args)
-	}
-	amt, err := strconv.Atoi(args[0])
-	if err != nil {
-		return fmt.Errorf("call to put_cash wasn't integer: %v", err)
-	}
-	if amt < 0 {
-		return fmt.Errorf("amount passed to put_cash was negative: %v", err)
-	}
+func (m *MainScene) putCash(amt int) error {
 	m.putBills(amt)
-	if m.State == StateDismissing {
+	if m.state() == StateDismissing {
 		return yarn.Stop
 	}
 	return nil
@@ -1126,90 +1288,57 @@ func (m *MainScene) putCash(args []string) error {
 
 const TrashChance = 0.1
 
-func (m *MainScene) putCounter(args []string) error {
-	for _, arg := range args {
-		arg = strings.TrimSpace(arg)
-		if arg == "" {
-			continue
-		}
-		switch {
-		case arg == "check":
+func (m *MainScene) putCounter(items []CounterItem) error {
+	for _, item := range items {
+		switch item.Kind {
+		case "check":
 			check := m.randCheck()
 			m.put(check)
-		case arg == "empty_slip":
-			slip := m.randEmptySlip()
+		case "itemized_slip":
+			slip := m.randItemizedSlip()
 			m.Runner.SetDepositSlip(slip)
 			m.setupAccount(slip)
+			m.bindAccount()
 			m.put(slip)
-		case strings.HasPrefix(arg, "deposit_slip"):
-			val := -1
-			if len(arg) > 13 {
-				v, err := strconv.Atoi(strings.TrimPrefix(arg, "deposit_slip_"))
-				if err != nil {
-					debug.Println("bad call to put_counter with deposit_slip value with value:", arg)
-				} else {
-					val = v
-				}
+			m.putBills(slip.CashValue / 100)
+			for _, val := range slip.Checks {
+				m.put(m.newCheck(val))
 			}
-			slip := m.randDepositSlip(val)
+		case "empty_slip":
+			slip := m.randEmptySlip()
+			m.Runner.SetDepositSlip(slip)
+			m.setupAccount(slip)
+			m.bindAccount()
+			m.put(slip)
+		case "deposit_slip":
+			slip := m.randDepositSlip(item.Amount)
 			m.Runner.SetDepositSlip(slip)
 			m.setupAccount(slip) // just in time!
+			m.bindAccount()
 			m.put(slip)
-			m.putBills(slip.Value / 100)
+			m.putBills(slip.CashValue / 100)
 			if rand.Float64() < TrashChance {
 				m.put(randomTrash(m.randomCounterPos()))
 			}
-		case strings.HasPrefix(arg, "withdrawal_slip"):
-			val := -1
-			if len(arg) > 16 {
-				v, err := strconv.Atoi(strings.TrimPrefix(arg, "withdrawal_slip_"))
-				if err != nil {
-					debug.Println("bad call to put_counter with deposit_slip value with value:", arg)
-				} else {
-					val = v
-				}
-			}
-			slip := m.randWithdrawalSlip(val)
+		case "withdrawal_slip":
+			slip := m.randWithdrawalSlip(item.Amount)
 			m.Runner.SetDepositSlip(slip)
 			m.setupAccount(slip)
+			m.bindAccount()
 			m.put(slip)
-		case arg == "trash":
+		case "id":
+			m.put(m.newPhotoID(m.today()))
+		case "trash":
 			m.put(randomTrash(m.randomCounterPos()))
-		case arg == "bill_1":
-			m.putBill(1)
-		case arg == "bill_5":
-			m.putBill(5)
-		case arg == "bill_10":
-			m.putBill(10)
-		case arg == "bill_20":
-			m.putBill(20)
-		case arg == "bill_100":
-			m.putBill(100)
-		case arg == "stack_1":
-			m.putStack(1)
-		case arg == "stack_5":
-			m.putStack(5)
-		case arg == "stack_10":
-			m.putStack(10)
-		case arg == "stack_20":
-			m.putStack(20)
-		case arg == "stack_100":
-			m.putStack(100)
-		case arg == "coin_1":
-			m.Sprites = append(m.Sprites, newCoin(1, m.randomCounterPos()))
-		case arg == "coin_5":
-			m.Sprites = append(m.Sprites, newCoin(5, m.randomCounterPos()))
-		case arg == "coin_10":
-			m.Sprites = append(m.Sprites, newCoin(10, m.randomCounterPos()))
-		case arg == "coin_25":
-			m.Sprites = append(m.Sprites, newCoin(25, m.randomCounterPos()))
-		case arg == "coin_50":
-			m.Sprites = append(m.Sprites, newCoin(50, m.randomCounterPos()))
-		default:
-			debug.Printf("unrecognized argument to put_counter: %v", arg)
+		case "bill":
+			m.putBill(item.Amount)
+		case "stack":
+			m.putStack(item.Amount)
+		case "coin":
+			m.Sprites = append(m.Sprites, newCoin(item.Amount, m.randomCounterPos()))
 		}
 	}
-	if m.State == StateDismissing {
+	if m.state() == StateDismissing {
 		return yarn.Stop
 	}
 	return nil
@@ -1219,15 +1348,42 @@ func (m *MainScene) putCounter(args []string) error {
 func (m *MainScene) setupAccount(slip *DepositSlip) {
 	// TODO: sometimes they shouldn't have an account.
 	acctNum := fmt.Sprintf("%d", slip.AcctNum)
-	if _, ok := m.Day.Accounts[acctNum]; !ok {
-		m.Day.Accounts[acctNum] = &Account{
-			Owner:    m.Customer.CustomerName,
-			Number:   acctNum,
-			Checking: randomAccountValue(),
+	acct, ok := m.Day.Accounts[acctNum]
+	if !ok {
+		bal := randomAccountValue()
+		acct = &Account{
+			Owner:        m.Customer.CustomerName,
+			Number:       acctNum,
+			Checking:     bal,
+			StartBalance: bal,
 		}
+		m.Day.Accounts[acctNum] = acct
+	}
+	if slip.IsWithdrawal && acct.Checking < slip.Value { // customers only ask for money they have.
+		acct.Checking += slip.Value
+		acct.StartBalance += slip.Value
 	}
 }
 
+// customerAccount finds the account of the current customer by name, setting one up just in time if need be; the
+// teller can FIND it on the terminal.
+func (m *MainScene) customerAccount() *Account {
+	for _, acct := range m.Day.Accounts {
+		if acct.Owner == m.Customer.CustomerName {
+			return acct
+		}
+	}
+	acctNum := strconv.Itoa(m.customerAcctNumber())
+	bal := randomAccountValue()
+	m.Day.Accounts[acctNum] = &Account{
+		Owner:        m.Customer.CustomerName,
+		Number:       acctNum,
+		Checking:     bal,
+		StartBalance: bal,
+	}
+	return m.Day.Accounts[acctNum]
+}
+
 func (m *MainScene) putCoins(amt int) {
 	var amts []int
 	for amt > 0 {
@@ -1300,11 +1456,20 @@ type Stack struct {
 
 type DepositSlip struct {
 	*BaseSprite
-	Value         int
-	ForDeposit    bool
-	ForWithdrawal bool
+	Value         int  // Value is the amount written on the slip.
+	CashValue     int  // CashValue is the value of the cash handed over with a deposit; differs from Value for SlipErrorAmount.
+	ForDeposit    bool // ForDeposit means the deposit box is checked.
+	ForWithdrawal bool // ForWithdrawal means the withdrawal box is checked.
+	IsWithdrawal  bool // IsWithdrawal means the customer is taking cash out, whichever boxes are checked.
 	AcctNum       int
-	IsWrong       bool // IsWrong means the customer did not fill out this paperwork correctly.
+	WrittenAcct   string // WrittenAcct is the account number as written on the slip; differs from AcctNum for SlipErrorAcctDigits.
+	Signature     string // Signature is the name signed on the slip; empty if unsigned.
+	IsWrong       bool   // IsWrong means the customer did not fill out this paperwork correctly.
+	Error         SlipError
+
+	Itemized bool  // Itemized slips list cash and checks separately, with Value as the total.
+	Cash     int   // Cash is the cash subtotal written on an itemized slip.
+	Checks   []int // Checks are the amounts of each check written on an itemized slip.
 }
 
 var depositSlipColor = colornames.Black
@@ -1314,51 +1479,113 @@ func (m *MainScene) put(sprite Sprite) {
 }
 
 func (m *MainScene) randEmptySlip() *DepositSlip {
-	return m.randSlip("deposit_slip.png", -1)
+	return m.randSlip(false, false, -1)
 }
 
 func (m *MainScene) randDepositSlip(val int) *DepositSlip {
-	result := m.randSlip("deposit_slip_deposit.png", val)
-	result.ForDeposit = true
-	return result
+	return m.randSlip(true, false, val)
 }
 
 func (m *MainScene) randWithdrawalSlip(val int) *DepositSlip {
-	result := m.randSlip("deposit_slip_withdrawal.png", val)
-	result.ForWithdrawal = true
-	return result
+	return m.randSlip(false, true, val)
 }
 
 var MaxTransactionValue = 1000 // TODO: make this go _DOWN_ as the days go on.
 
-func (m *MainScene) randSlip(path string, val int) *DepositSlip {
-	img := ebiten.NewImage(43, 32)
-	img.DrawImage(Resources.GetImage(path), nil)
+const slipWidth, slipHeight = 43, 32
+const itemizedLineHeight = 8
+const MaxItemizedChecks = 3
 
+func (m *MainScene) newSlip(forDeposit, forWithdrawal bool, val int) *DepositSlip {
 	pos := m.randomCounterPos()
 	slip := &DepositSlip{
-		AcctNum:    randomAcctNumber(),
-		Value:      randomTransactionValue(),
-		BaseSprite: &BaseSprite{Img: img, X: pos.X, Y: pos.Y},
+		AcctNum:       m.customerAcctNumber(),
+		Value:         randomTransactionValue(),
+		ForDeposit:    forDeposit,
+		ForWithdrawal: forWithdrawal,
+		IsWithdrawal:  forWithdrawal,
+		Signature:     m.Customer.CustomerName,
+		BaseSprite:    &BaseSprite{Img: ebiten.NewImage(slipWidth, slipHeight), X: pos.X, Y: pos.Y},
 	}
 	if val > 0 {
 		slip.Value = val
 	}
+	slip.CashValue = slip.Value
+	slip.WrittenAcct = strconv.Itoa(slip.AcctNum)
+	return slip
+}
+
+func (m *MainScene) randSlip(forDeposit, forWithdrawal bool, val int) *DepositSlip {
+	slip := m.newSlip(forDeposit, forWithdrawal, val)
+	if forDeposit || forWithdrawal { // empty slips are already wrong enough
+		slip.applyError(slip.maybeError())
+	}
+	m.drawSlip(slip)
+	return slip
+}
+
+// randItemizedSlip creates a deposit slip listing cash and between 1 and MaxItemizedChecks checks.
+func (m *MainScene) randItemizedSlip() *DepositSlip {
+	slip := m.newSlip(true, false, -1)
+	slip.Itemized = true
+	slip.Checks = make([]int, rand.Intn(MaxItemizedChecks)+1)
+	for i := range slip.Checks {
+		slip.Checks[i] = randomItemizedCheckValue()
+	}
+	slip.Cash = slip.CashValue
+	slip.Value = slip.Cash + slip.ChecksTotal()
+	slip.Img = ebiten.NewImage(slipWidth, slipHeight+itemizedLineHeight*(len(slip.Checks)+1))
+	slip.applyError(slip.maybeError())
+	m.drawSlip(slip)
+	return slip
+}
+
+// drawSlip renders the slip as the customer filled it out, mistakes and all.
+func (m *MainScene) drawSlip(slip *DepositSlip) {
+	path := "deposit_slip_empty"
+	switch {
+	case slip.ForDeposit && slip.ForWithdrawal:
+		path = "deposit_slip_both"
+	case slip.ForDeposit:
+		path = "deposit_slip_deposit"
+	case slip.ForWithdrawal:
+		path = "deposit_slip_withdrawal"
+	}
+	img := slip.Img
+	img.Clear()
+	img.DrawImage(Resources.GetImage(path), nil)
 
 	m.txt.SetColor(depositSlipColor)
 	m.txt.SetSizePx(10)
 	m.txt.SetFont(Resources.GetFont(DialogFont))
 	m.txt.SetTarget(img)
-	m.txt.Draw(fmt.Sprintf("#%d", slip.AcctNum), 14, 2)
+	m.txt.Draw("#"+slip.WrittenAcct, 14, 2)
 
 	m.txt.SetFont(Resources.GetFont(DialogFont)) // TODO: make look like handwriting
 	m.txt.SetSizePx(10)
-	m.txt.Draw(fmt.Sprintf("%d.00", slip.Value/100), 16, 17)
+	m.txt.Draw(fmtDollars(slip.Value), 16, 17)
 
-	// TODO: signature?
-	return slip
+	if slip.Signature != "" { // sign on the dotted line
+		m.txt.SetSizePx(8)
+		m.txt.SetFont(Resources.RandomScriptFont())
+		m.txt.Draw(slip.Signature, 16, 8)
+	}
+
+	if slip.Itemized { // list each item below the total
+		y := slipHeight - 3
+		img.SubImage(image.Rect(0, y, slipWidth, img.Bounds().Dy())).(*ebiten.Image).Fill(slipPaperColor)
+		m.txt.SetFont(Resources.GetFont(DialogFont))
+		m.txt.SetSizePx(8)
+		m.txt.Draw(T("slip.cash", fmtDollars(slip.Cash)), 3, y)
+		for idx, check := range slip.Checks {
+			y += itemizedLineHeight
+			m.txt.Draw(T("slip.check", idx+1, fmtDollars(check)), 3, y)
+		}
+	}
 }
 
+var slipPaperColor = h2c("f5daa7")
+
 type Check struct {
 	*BaseSprite
 	reverse  *ebiten.Image // swapped with front when right-clicked.
@@ -1366,6 +1593,7 @@ type Check struct {
 	Signed   bool
 	Endorsed bool
 	Valid    bool
+	Routing  string
 }
 
 func (c *Check) flip() {
@@ -1373,6 +1601,10 @@ func (c *Check) flip() {
 }
 
 func (m *MainScene) randCheck() *Check {
+	return m.newCheck(randomCheckValue())
+}
+
+func (m *MainScene) newCheck(value int) *Check {
 	front := ebiten.NewImage(76, 32)
 	back := ebiten.NewImage(32, 76)
 	opts := &ebiten.DrawImageOptions{}
@@ -1384,16 +1616,19 @@ func (m *MainScene) randCheck() *Check {
 	check := &Check{
 		BaseSprite: &BaseSprite{Img: front, X: pos.X, Y: pos.Y},
 		reverse:    back,
-		Value:      randomCheckValue(),
+		Value:      value,
 		Signed:     randomSignedValue(),
 		Endorsed:   randomEndorsedValue(),
 		Valid:      randomCheckValidity(),
 	}
+	check.Routing = randomRoutingNumber(check.Valid)
 
 	m.txt.SetFont(Resources.GetFont(DialogFont)) // TODO: make look like handwriting
 	m.txt.SetSizePx(10)
 	m.txt.SetTarget(front)
-	m.txt.Draw(fmt.Sprintf("%d.00", check.Value/100), 50, 2)
+	m.txt.Draw(fmtDollars(check.Value), 50, 2)
+	m.txt.SetSizePx(8)
+	m.txt.Draw(check.Routing, 3, 22)
 
 	if check.Signed {
 		m.txt.SetColor(depositSlipColor)
@@ -1454,6 +1689,10 @@ func randomCheckValue() int {
 	return rand.Intn(10000)
 }
 
+func randomItemizedCheckValue() int {
+	return (rand.Intn(200) + 1) * 100
+}
+
 func randomAccountValue() int {
 	return rand.Intn(10000) // TODO: make this more realistic
 }
@@ -1462,6 +1701,14 @@ func randomTransactionValue() int {
 	return rand.Intn(MaxTransactionValue) * 100 // TODO: make this more realistic
 }
 
+// customerAcctNumber is the account number of the current customer; regulars always use the same one.
+func (m *MainScene) customerAcctNumber() int {
+	if m.Customer != nil && m.Customer.Regular != nil {
+		return m.Customer.Regular.AcctNum
+	}
+	return randomAcctNumber()
+}
+
 func randomAcctNumber() int {
 	return rand.Intn(89999) + 10000
 }
@@ -1491,6 +1738,7 @@ const (
 	IntentDeposit      = "deposit"
 	IntentCashCheck    = "cash_check"
 	IntentDepositCheck = "deposit_check"
+	IntentRobbery      = "robbery"
 )
 
 type Customer struct {
@@ -1503,6 +1751,22 @@ type Customer struct {
 	CustomerName   string
 	DepositSlip    *DepositSlip // DepositSlip may be nil for some customers.
 	IsRude         bool
+	Mood           Mood
+	Patience       float64 // Patience runs from 1 down to 0, when the customer storms off.
+	Walk           WalkStyle
+	Fade           float64 // Fade is 0 for a fully visible customer, and 1 for an invisible one.
+	Regular        *Regular
+	Refused        bool // Refused is set when the customer's slip is handed back to them.
+	Overpaid       bool // Overpaid is set when the customer is handed money they didn't ask for.
+	complaints     int  // complaints is the number of patience thresholds crossed so far.
+
+	spec       *PortraitSpec // spec is how the portrait was composed; nil for simple portraits.
+	emotion    string
+	neutralImg *ebiten.Image            // neutralImg is the portrait without any emotion.
+	variants   map[string]*ebiten.Image // variants caches the portrait for each emotion shown so far; nil for emotions with no variant.
+
+	mut         sync.Mutex // mut guards nextEmotion, which is queued from the dialogue goroutine.
+	nextEmotion *string
 }
 
 // clampToCounter clamps the provided point to the counter range (hardcoded)
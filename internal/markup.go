@@ -0,0 +1,174 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"github.com/DrJosh9000/yarn"
+	"github.com/ebitenui/ebitenui/utilities/colorutil"
+	"golang.org/x/image/colornames"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Markup attributes style the text of a line, e.g. [b]$100[/b], [color=red]NOW[/color] or [pause=500/]. Each also
+// changes how quickly the text it styles crawls by; see markupPace.
+const (
+	MarkupBold  = "b"     // MarkupBold draws text heavier, for emphasis.
+	MarkupColor = "color" // MarkupColor draws text in a colour named like red, or written in hex like #ff8000.
+	MarkupWave  = "wave"  // MarkupWave bobs each letter up and down; wave=n sets how far, in pixels.
+	MarkupShake = "shake" // MarkupShake jitters each letter; shake=n sets how far, in pixels.
+	MarkupPause = "pause" // MarkupPause stops the crawl for a number of milliseconds.
+)
+
+// markupPace multiplies the crawl speed of text inside each attribute; emphasis is drawn out and yelling spills out.
+var markupPace = map[string]float64{
+	MarkupBold:  0.5,
+	MarkupColor: 0.75,
+	MarkupWave:  0.6,
+	MarkupShake: 1.5,
+}
+
+const defaultWave = 2
+const defaultPause = 500 * time.Millisecond
+
+// Markup is a run of text styled by a markup attribute.
+type Markup struct {
+	Start, End int // Start and End are offsets into the text, in runes; a pause has Start == End.
+	Name       string
+
+	Color color.Color   // Color is the colour of MarkupColor text.
+	Size  int           // Size is how far MarkupWave and MarkupShake text moves, in pixels.
+	Pause time.Duration // Pause is how long MarkupPause stops the crawl.
+}
+
+// parseMarkup reads the markup attributes of a rendered line. Attributes which can't be read are left out, and
+// reported in the error.
+func parseMarkup(s *yarn.AttributedString) ([]Markup, error) {
+	text := s.String()
+	var result []Markup
+	var errs []error
+	s.ScanAttribEvents(func(pos int, atts []*yarn.Attribute) {
+		for _, a := range atts {
+			if pos != a.Start { // attributes are visited where they start and where they end.
+				continue
+			}
+			m, err := newMarkup(a.Name, a.Props[a.Name])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			end := a.End
+			if end < a.Start {
+				end = len(text)
+			}
+			m.Start, m.End = utf8.RuneCountInString(text[:a.Start]), utf8.RuneCountInString(text[:end])
+			result = append(result, m)
+		}
+	})
+	return result, errors.Join(errs...)
+}
+
+// newMarkup reads an attribute and its value, e.g. color and red from [color=red].
+func newMarkup(name, value string) (Markup, error) {
+	m := Markup{Name: name}
+	value = strings.TrimSpace(value)
+	switch name {
+	case MarkupBold:
+	case MarkupColor:
+		m.Color = markupColor(value)
+		if m.Color == nil {
+			return m, fmt.Errorf("color must be a colour name or #rrggbb; got %q", value)
+		}
+	case MarkupWave, MarkupShake:
+		m.Size = defaultWave
+		if name == MarkupShake {
+			m.Size = defaultShake
+		}
+		if value != "" {
+			size, err := strconv.Atoi(value)
+			if err != nil || size < 0 {
+				return m, fmt.Errorf("%s must be a number of pixels; got %q", name, value)
+			}
+			m.Size = size
+		}
+	case MarkupPause:
+		m.Pause = defaultPause
+		if value != "" {
+			ms, err := strconv.Atoi(value)
+			if err != nil || ms < 0 {
+				return m, fmt.Errorf("pause must be a number of milliseconds; got %q", value)
+			}
+			m.Pause = time.Duration(ms) * time.Millisecond
+		}
+	default:
+		return m, fmt.Errorf("unknown markup [%s]", name)
+	}
+	return m, nil
+}
+
+// markupColor reads a colour name like red, or a hex colour like #ff8000; nil if it's neither.
+func markupColor(value string) color.Color {
+	if c, ok := colornames.Map[strings.ToLower(value)]; ok {
+		return c
+	}
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) != 6 {
+		return nil
+	}
+	c, err := colorutil.HexToColor(hex)
+	if err != nil {
+		return nil
+	}
+	return c
+}
+
+// shiftMarkup moves markup along by the provided number of runes, for text put in front of the line.
+func shiftMarkup(markup []Markup, by int) []Markup {
+	result := make([]Markup, len(markup))
+	for i, m := range markup {
+		m.Start, m.End = m.Start+by, m.End+by
+		result[i] = m
+	}
+	return result
+}
+
+// crawled is how many of the n runes of a line have crawled by after elapsed, at cps characters per second, once the
+// pace of their markup and any pauses before them are taken into account.
+func crawled(markup []Markup, n int, elapsed time.Duration, cps float64) int {
+	if len(markup) == 0 {
+		return int(elapsed.Seconds() * cps)
+	}
+	var t time.Duration
+	for i := 0; i < n; i++ {
+		t += crawlPause(markup, i) + time.Duration(float64(time.Second)/(cps*crawlPace(markup, i)))
+		if t > elapsed {
+			return i
+		}
+	}
+	return n
+}
+
+// crawlPace is how much faster than usual the rune at idx crawls by.
+func crawlPace(markup []Markup, idx int) float64 {
+	pace := 1.0
+	for _, m := range markup {
+		if p, ok := markupPace[m.Name]; ok && m.Start <= idx && idx < m.End {
+			pace *= p
+		}
+	}
+	return pace
+}
+
+// crawlPause is how long the crawl stops before the rune at idx.
+func crawlPause(markup []Markup, idx int) time.Duration {
+	var result time.Duration
+	for _, m := range markup {
+		if m.Name == MarkupPause && m.Start == idx {
+			result += m.Pause
+		}
+	}
+	return result
+}
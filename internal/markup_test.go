@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"github.com/DrJosh9000/yarn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/colornames"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseMarkup(t *testing.T) {
+	s := render(t, `Só [b]$50[/b]?[pause pause="250"/] [color color="red"]Now[/color] [shake]NOW![/shake]`)
+	markup, err := parseMarkup(s)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Só $50? Now NOW!", s.String())
+	assert.EqualValues(t, []Markup{
+		{Start: 3, End: 6, Name: MarkupBold},
+		{Start: 7, End: 7, Name: MarkupPause, Pause: 250 * time.Millisecond},
+		{Start: 8, End: 11, Name: MarkupColor, Color: colornames.Red},
+		{Start: 12, End: 16, Name: MarkupShake, Size: defaultShake},
+	}, markup)
+
+	_, err = parseMarkup(render(t, `[blink]Hi[/blink] [color color="nope"]there[/color]`))
+	assert.ErrorContains(t, err, "unknown markup [blink]")
+	assert.ErrorContains(t, err, `got "nope"`)
+}
+
+func TestCrawled(t *testing.T) {
+	markup := []Markup{{Start: 2, End: 4, Name: MarkupBold}, {Start: 4, End: 4, Name: MarkupPause, Pause: time.Second}}
+
+	assert.Equal(t, 2, crawled(markup, 6, 200*time.Millisecond, 10))  // plain text crawls at 10 cps...
+	assert.Equal(t, 3, crawled(markup, 6, 500*time.Millisecond, 10))  // ...bold at half that...
+	assert.Equal(t, 4, crawled(markup, 6, 1500*time.Millisecond, 10)) // ...then it stops for a second.
+	assert.Equal(t, 6, crawled(markup, 6, time.Hour, 10))
+	assert.Equal(t, 5, crawled(nil, 6, 500*time.Millisecond, 10))
+}
+
+func render(t *testing.T, text string) *yarn.AttributedString {
+	csv := "id,text,file,node,lineNumber\nline:1,\"" + strings.ReplaceAll(text, `"`, `""`) + "\",Test.yarn,Start,1\n"
+	st, err := yarn.ReadStringTable(strings.NewReader(csv), "en-US")
+	require.NoError(t, err)
+	s, err := st.Render(yarn.Line{ID: "line:1"})
+	require.NoError(t, err)
+	return s
+}
@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Mood is how a customer feels about the service they're getting, from MoodFurious to MoodDelighted.
+type Mood int
+
+const (
+	MoodFurious Mood = iota - 2
+	MoodAnnoyed
+	MoodNeutral
+	MoodPleased
+	MoodDelighted
+)
+
+const (
+	VarMood     = "$mood"      // VarMood is the customer's mood, from -2 (furious) to 2 (delighted).
+	VarMoodName = "$mood_name" // VarMoodName is the name of the customer's mood; e.g. "annoyed".
+)
+
+// QuickService is how much patience the customer must have left to be pleased by the speed of service.
+const QuickService = 0.75
+
+func (m Mood) String() string {
+	switch {
+	case m <= MoodFurious:
+		return "furious"
+	case m == MoodAnnoyed:
+		return "annoyed"
+	case m == MoodPleased:
+		return "pleased"
+	case m >= MoodDelighted:
+		return "delighted"
+	}
+	return "neutral"
+}
+
+// startingMood is the mood a customer arrives in; rude customers show up annoyed.
+func startingMood(rude bool) Mood {
+	if rude {
+		return MoodAnnoyed
+	}
+	return MoodNeutral
+}
+
+// Barks are the lines a customer might say in response to something, by mood.
+type Barks struct {
+	Happy, Neutral, Angry []string
+}
+
+// For picks a random line suiting the provided mood, falling back to the neutral lines. Rude customers turn nasty as
+// soon as they're annoyed; polite customers mind their manners until they're furious.
+func (b Barks) For(mood Mood, rude bool) string {
+	lines := b.Neutral
+	angry := mood <= MoodFurious || rude && mood < MoodNeutral
+	switch {
+	case angry && len(b.Angry) > 0:
+		lines = b.Angry
+	case mood > MoodNeutral && len(b.Happy) > 0:
+		lines = b.Happy
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return randSlice(lines)
+}
+
+// changeMood moves the current customer's mood by the provided amount, lets Yarn know, and has them say something
+// from the named barks in their new mood.
+func (m *MainScene) changeMood(delta int, barks string) {
+	c := m.Customer
+	c.Mood = Mood(clamp(int(c.Mood)+delta, int(MoodFurious), int(MoodDelighted)))
+	m.setMoodVars()
+	m.bark(Lang.Barks(barks).For(c.Mood, c.IsRude))
+}
+
+func (m *MainScene) setMoodVars() {
+	m.Runner.SetVar(VarMood, float32(m.Customer.Mood))
+	m.Runner.SetVar(VarMoodName, m.Customer.Mood.String())
+}
+
+// serviceMood is how the customer's mood changes once they've been served; quick service pleases everybody, and
+// withdrawals also depend on getting the cash they asked for.
+func (c *Customer) serviceMood() int {
+	var result int
+	if c.Patience > QuickService {
+		result++
+	}
+	if c.CustomerIntent != IntentWithdraw || c.DepositSlip == nil {
+		return result
+	}
+	switch {
+	case c.CashInHand < c.DepositSlip.Value:
+		result--
+	case c.CashInHand > c.DepositSlip.Value:
+		result++
+	}
+	return result
+}
+
+// moodOffset is how far the customer's portrait is moved to show how they feel; furious customers shake, and
+// delighted customers hop.
+func (c *Customer) moodOffset() (x, y float64) {
+	secs := time.Now().Sub(startTime).Seconds()
+	switch {
+	case c.Mood <= MoodFurious:
+		return float64(rand.Intn(3) - 1), 0
+	case c.Mood >= MoodDelighted:
+		return 0, -math.Abs(math.Sin(secs*2*math.Pi)) * 2
+	}
+	return 0, 0
+}
@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBarks_For(t *testing.T) {
+	barks := Barks{Happy: []string{"yay"}, Neutral: []string{"ok"}, Angry: []string{"grr"}}
+
+	assert.EqualValues(t, "grr", barks.For(MoodFurious, true))
+	assert.EqualValues(t, "grr", barks.For(MoodAnnoyed, true))
+	assert.EqualValues(t, "ok", barks.For(MoodNeutral, true))
+	assert.EqualValues(t, "yay", barks.For(MoodDelighted, true))
+
+	assert.EqualValues(t, "grr", barks.For(MoodFurious, false))
+	assert.EqualValues(t, "ok", barks.For(MoodAnnoyed, false)) // polite customers stay polite a little longer.
+
+	assert.EqualValues(t, "ok", Barks{Neutral: []string{"ok"}}.For(MoodFurious, true))
+}
+
+func TestMood_String(t *testing.T) {
+	assert.EqualValues(t, "furious", MoodFurious.String())
+	assert.EqualValues(t, "neutral", MoodNeutral.String())
+	assert.EqualValues(t, "delighted", MoodDelighted.String())
+}
+
+func TestCustomer_ServiceMood(t *testing.T) {
+	slip := &DepositSlip{Value: 5000, IsWithdrawal: true}
+	assert.EqualValues(t, 1, (&Customer{CustomerIntent: IntentDeposit, Patience: 1}).serviceMood())
+	assert.EqualValues(t, 0, (&Customer{CustomerIntent: IntentDeposit, Patience: QuickService}).serviceMood())
+	assert.EqualValues(t, 1, (&Customer{CustomerIntent: IntentCashCheck, Patience: 1}).serviceMood())
+
+	assert.EqualValues(t, 1, (&Customer{CustomerIntent: IntentWithdraw, Patience: 1, DepositSlip: slip, CashInHand: 5000}).serviceMood())
+	assert.EqualValues(t, 2, (&Customer{CustomerIntent: IntentWithdraw, Patience: 1, DepositSlip: slip, CashInHand: 6000}).serviceMood())
+	assert.EqualValues(t, -1, (&Customer{CustomerIntent: IntentWithdraw, Patience: 0.5, DepositSlip: slip, CashInHand: 4000}).serviceMood())
+}
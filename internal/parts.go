@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/Frabjous-Studios/bankwave/internal/debug"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+const partsManifest = "parts_manifest.txt"
+
+const (
+	PartHead      = "head"
+	PartBody      = "body"
+	PartHat       = "hat"
+	PartAccessory = "accessory"
+	PartPalette   = "palette"
+)
+
+// TagScripted marks parts which are only used when asked for by name.
+const TagScripted = "scripted"
+
+// TagRude marks parts which suit rude customers.
+const TagRude = "rude"
+
+// Part is a piece of a portrait, such as a head or a body, along with its tags.
+type Part struct {
+	Category string
+	Image    string
+	Tags     []string
+}
+
+func (p *Part) HasTag(tag string) bool {
+	return contains(p.Tags, tag)
+}
+
+// hasTags returns true if the part has every one of the provided tags.
+func (p *Part) hasTags(tags []string) bool {
+	for _, t := range tags {
+		if !p.HasTag(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// PartsManifest is every portrait part, by category.
+type PartsManifest map[string][]*Part
+
+// parseManifest parses a parts manifest; see parts_manifest.txt for the format.
+func parseManifest(r io.Reader) (PartsManifest, error) {
+	result := make(PartsManifest)
+	scanner := bufio.NewScanner(r)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: expected a category and an image: %q", lineNum, line)
+		}
+		p := &Part{Category: fields[0], Image: fields[1], Tags: fields[2:]}
+		result[p.Category] = append(result[p.Category], p)
+	}
+	return result, scanner.Err()
+}
+
+// Images lists the images of every part in the manifest; palettes aren't images.
+func (m PartsManifest) Images() []string {
+	var result []string
+	for category, parts := range m {
+		if category == PartPalette {
+			continue
+		}
+		for _, p := range parts {
+			result = append(result, p.Image)
+		}
+	}
+	return result
+}
+
+// Sample picks a random part from the provided category which has all the required tags, preferring parts which also
+// have all the preferred tags. If nothing has the required tags, any part will do, and the mismatch is logged. Scripted
+// parts are only picked if they're asked for. Returns nil if the category is empty.
+func (m PartsManifest) Sample(category string, required, preferred []string) *Part {
+	return m.sample(rand.Intn, category, required, preferred)
+}
+
+// sample is Sample, using the provided source of randomness.
+func (m PartsManifest) sample(intn func(int) int, category string, required, preferred []string) *Part {
+	candidates := m.filter(category, append(required[:len(required):len(required)], preferred...))
+	if len(candidates) == 0 {
+		candidates = m.filter(category, required)
+	}
+	if len(candidates) == 0 && len(required) > 0 {
+		debug.Printf("no %s part has all the tags %v; picking any %s", category, required, category)
+		candidates = m.filter(category, nil)
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+	return candidates[intn(len(candidates))]
+}
+
+func (m PartsManifest) filter(category string, tags []string) []*Part {
+	var result []*Part
+	for _, p := range m[category] {
+		if p.HasTag(TagScripted) && !contains(tags, TagScripted) {
+			continue
+		}
+		if p.hasTags(tags) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestPartsManifest_Sample(t *testing.T) {
+	manifest, err := parseManifest(strings.NewReader(`
+# comment
+head robo.png robot
+head cat.png animal rude
+head old.png alien scripted
+body suit.png formal
+`))
+	assert.NoError(t, err)
+	assert.Len(t, manifest[PartHead], 3)
+
+	for i := 0; i < 20; i++ {
+		assert.EqualValues(t, "robo.png", manifest.Sample(PartHead, []string{"robot"}, []string{TagRude}).Image)
+		assert.EqualValues(t, "cat.png", manifest.Sample(PartHead, nil, []string{TagRude}).Image)
+		assert.NotEqualValues(t, "old.png", manifest.Sample(PartHead, nil, nil).Image)
+		assert.EqualValues(t, "suit.png", manifest.Sample(PartBody, []string{"robot"}, nil).Image)
+	}
+	assert.EqualValues(t, "old.png", manifest.Sample(PartHead, []string{TagScripted}, nil).Image)
+	assert.Nil(t, manifest.Sample(PartHat, nil, nil))
+
+	_, err = parseManifest(strings.NewReader("head"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,58 @@
+package internal
+
+import "strings"
+
+// PatienceSeconds is how long a polite customer will wait at an empty bank before storming off.
+const PatienceSeconds = 90
+
+// RudePatienceFactor is how much faster rude customers lose their patience.
+const RudePatienceFactor = 1.8
+
+// PatienceThresholds are the levels of patience at which customers start complaining.
+var PatienceThresholds = []float64{0.5, 0.2}
+
+const VarStormOffs = "$storm_offs"
+
+// DrainPatience drains the customer's patience over one tick; customers are less patient the longer the line is
+// behind them. Returns true when the customer's patience crosses a threshold, or runs out.
+func (c *Customer) DrainPatience(pressure float64) bool {
+	if c.Patience <= 0 {
+		return false
+	}
+	rate := (1 + pressure) / (PatienceSeconds * TPS)
+	if c.IsRude {
+		rate *= RudePatienceFactor
+	}
+	c.Patience -= rate
+	if c.Patience <= 0 {
+		return true
+	}
+	if c.complaints < len(PatienceThresholds) && c.Patience < PatienceThresholds[c.complaints] {
+		c.complaints++
+		return true
+	}
+	return false
+}
+
+// updatePatience drains the patience of random customers who are left waiting at the window, and sends them off if it
+// runs out.
+func (m *MainScene) updatePatience() {
+	if m.State != StateConversing || m.Customer == nil || !strings.HasPrefix(m.Runner.CurrNodeName, "Random") {
+		return
+	}
+	if !m.Customer.DrainPatience(m.queue.Pressure()) {
+		return
+	}
+	if m.Customer.Patience > 0 {
+		m.changeMood(-1, ImpatientBarks)
+		return
+	}
+	m.Day.StormOffs = append(m.Day.StormOffs, m.Customer.CustomerName)
+	m.Runner.SetVar(VarStormOffs, float32(len(m.Day.StormOffs)))
+	snd := Resources.GetSound(m.Game.ACtx, "Buzzer-1.ogg")
+	snd.Rewind()
+	snd.Play()
+	m.Customer.Walk = WalkStorm
+	m.depart()
+	m.changeMood(int(MoodFurious-m.Customer.Mood), StormOffBarks)
+}
@@ -0,0 +1,289 @@
+package internal
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/Frabjous-Studios/bankwave/internal/debug"
+	"github.com/hajimehoshi/ebiten/v2"
+	img2 "image"
+	"image/color"
+	"image/draw"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+)
+
+// PortraitLayer is a single image in a portrait, recolored by swapping its hue.
+type PortraitLayer struct {
+	Image    string
+	HueShift int // HueShift is how far to shift the hue of every colorful pixel, in degrees.
+}
+
+// PortraitSpec describes a layered portrait; the same spec always composes the same image.
+type PortraitSpec struct {
+	Body, Head     PortraitLayer
+	Hat, Accessory PortraitLayer // Hat and Accessory are optional.
+	Held           bool          // Held is set when the accessory is carried in front of the body, rather than worn.
+}
+
+const (
+	HatChance           = 0.3
+	AccessoryChance     = 0.35
+	BodySwapChance      = 0.7
+	HeadSwapChance      = 0.25
+	AccessorySwapChance = 0.6
+
+	hatSink        = 3 // hatSink is how far hats sit down over the top of the head.
+	portraitBodyY  = 33
+	portraitSize   = 100
+	recentHeadsMax = 6 // recentHeadsMax is how many heads to remember, to keep them from repeating.
+)
+
+var hueShifts = []int{60, 120, 180, 240, 300}
+
+var (
+	recentHeadsMut sync.Mutex // recentHeadsMut guards recentHeads, which portraits are picked into from the dialogue goroutine.
+	recentHeads    []string
+)
+
+// randomPortraitSpec picks parts for a portrait, with all the required tags, preferring parts with the preferred tags.
+func randomPortraitSpec(rng *rand.Rand, required, preferred []string) PortraitSpec {
+	parts := Resources.parts
+	head := parts.sample(rng.Intn, PartHead, required, preferred)
+	body := parts.sample(rng.Intn, PartBody, required, preferred)
+	spec := PortraitSpec{
+		Head: PortraitLayer{Image: head.Image, HueShift: maybeShift(rng, HeadSwapChance)},
+		Body: PortraitLayer{Image: body.Image, HueShift: maybeShift(rng, BodySwapChance)},
+	}
+	headBounds := Resources.partBounds(head.Image)
+	if hat := parts.sample(rng.Intn, PartHat, nil, preferred); hat != nil && rng.Float64() < HatChance {
+		if headBounds.Min.Y+hatSink >= Resources.partBounds(hat.Image).Dy() { // skip hats that don't fit.
+			spec.Hat = PortraitLayer{Image: hat.Image, HueShift: maybeShift(rng, AccessorySwapChance)}
+		}
+	}
+	if acc := parts.sample(rng.Intn, PartAccessory, nil, preferred); acc != nil && rng.Float64() < AccessoryChance {
+		bareFace := head.HasTag("humanoid") && !head.HasTag("shades") && !head.HasTag("glasses")
+		if acc.HasTag("held") || bareFace {
+			spec.Accessory = PortraitLayer{Image: acc.Image, HueShift: maybeShift(rng, AccessorySwapChance)}
+			spec.Held = acc.HasTag("held")
+		}
+	}
+	return spec
+}
+
+func maybeShift(rng *rand.Rand, chance float64) int {
+	if rng.Float64() >= chance {
+		return 0
+	}
+	return hueShifts[rng.Intn(len(hueShifts))]
+}
+
+// newSeededPortrait creates a random portrait which is the same every time for the same seed.
+func newSeededPortrait(seed int64, required, preferred []string) *Customer {
+	return newComposedPortrait(randomPortraitSpec(rand.New(rand.NewSource(seed)), required, preferred))
+}
+
+func newComposedPortrait(spec PortraitSpec) *Customer {
+	return &Customer{
+		ImageKey: fmt.Sprintf("%s:%s", spec.Body.Image, spec.Head.Image),
+		spec:     &spec,
+		BaseSprite: &BaseSprite{
+			Img: ebiten.NewImageFromImage(Resources.ComposePortrait(spec)),
+			X:   portraitStartX,
+			Y:   portraitStartY,
+		},
+	}
+}
+
+// ComposePortrait layers the body, head, hat and accessory of the provided portrait, swapping the palette of each.
+func (r *resources) ComposePortrait(spec PortraitSpec) *img2.RGBA {
+	out := img2.NewRGBA(rect(0, 0, portraitSize, portraitSize))
+	r.drawLayer(out, spec.Body, img2.Pt(0, portraitBodyY))
+	r.drawLayer(out, spec.Head, img2.Pt(0, 0))
+
+	head := r.partBounds(spec.Head.Image)
+	if spec.Hat.Image != "" {
+		hat := r.partBounds(spec.Hat.Image)
+		r.drawLayer(out, spec.Hat, img2.Pt((head.Min.X+head.Max.X-hat.Dx())/2, head.Min.Y+hatSink-hat.Dy()))
+	}
+	if spec.Accessory.Image != "" {
+		acc := r.partBounds(spec.Accessory.Image)
+		var pos img2.Point
+		switch {
+		case !spec.Held: // over the eyes
+			pos = img2.Pt((head.Min.X+head.Max.X-acc.Dx())/2, head.Min.Y+head.Dy()*2/5-acc.Dy()/2)
+		case acc.Dx() <= 12: // in the right hand
+			pos = img2.Pt(64, portraitSize-acc.Dy())
+		default: // in the left hand
+			pos = img2.Pt(18, portraitSize-acc.Dy())
+		}
+		r.drawLayer(out, spec.Accessory, pos)
+	}
+	return out
+}
+
+// drawLayer draws the provided layer onto dst at the provided point.
+func (r *resources) drawLayer(dst *img2.RGBA, layer PortraitLayer, at img2.Point) {
+	src := r.decoded(layer.Image)
+	if src == nil {
+		return
+	}
+	swap := r.paletteSwap(layer.HueShift)
+	b := src.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			c := src.RGBAAt(x, y)
+			if c.A == 0 {
+				continue
+			}
+			if to, ok := swap[c]; ok {
+				c = to
+			}
+			dst.SetRGBA(x+at.X, y+at.Y, c)
+		}
+	}
+}
+
+// decoded returns the decoded pixels of the provided image, for compositing on the CPU.
+func (r *resources) decoded(path string) *img2.RGBA {
+	if r.cpuImages == nil {
+		r.cpuImages = make(map[string]*img2.RGBA)
+	}
+	if img, ok := r.cpuImages[path]; ok {
+		return img
+	}
+	img, err := decodeImage(path)
+	if err != nil {
+		debug.Printf("failed to decode portrait part: %s: %v", path, err)
+		r.cpuImages[path] = nil
+		return nil
+	}
+	rgba := img2.NewRGBA(img.Bounds())
+	draw.Draw(rgba, rgba.Bounds(), img, img.Bounds().Min, draw.Src)
+	r.cpuImages[path] = rgba
+	return rgba
+}
+
+// partBounds returns the bounds of the visible pixels in the provided image.
+func (r *resources) partBounds(path string) img2.Rectangle {
+	img := r.decoded(path)
+	if img == nil {
+		return img2.Rectangle{}
+	}
+	var result img2.Rectangle
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			if img.RGBAAt(x, y).A > 0 {
+				result = result.Union(rect(x, y, 1, 1))
+			}
+		}
+	}
+	return result
+}
+
+// Palette returns the colors of the game's palette, as listed by the first palette in the parts manifest.
+func (r *resources) Palette() []color.RGBA {
+	if r.palette != nil {
+		return r.palette
+	}
+	pals := r.parts[PartPalette]
+	if len(pals) == 0 {
+		debug.Println("no palette in parts manifest")
+		return nil
+	}
+	f, err := art.Open(fmt.Sprintf("gamedata/img/%s", pals[0].Image))
+	if err != nil {
+		debug.Printf("failed to open palette: %v", err)
+		return nil
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		hex := strings.Split(scanner.Text(), ",")[0] // original,day,night
+		if strings.HasPrefix(hex, "[") {
+			continue
+		}
+		r.palette = append(r.palette, color.RGBAModel.Convert(h2c(hex)).(color.RGBA))
+	}
+	return r.palette
+}
+
+// paletteSwap maps each colorful palette color to the palette color closest to it with its hue shifted by the provided
+// amount. Everything stays in the palette, so the day/night shader keeps working.
+func (r *resources) paletteSwap(shift int) map[color.RGBA]color.RGBA {
+	if shift == 0 {
+		return nil
+	}
+	if r.swaps == nil {
+		r.swaps = make(map[int]map[color.RGBA]color.RGBA)
+	}
+	if swap, ok := r.swaps[shift]; ok {
+		return swap
+	}
+	const minSaturation = 0.25 // greys, black and white are left alone.
+	swap := make(map[color.RGBA]color.RGBA)
+	pal := r.Palette()
+	for _, from := range pal {
+		h, s, l := rgbToHSL(from)
+		if s < minSaturation {
+			continue
+		}
+		target := math.Mod(h+float64(shift), 360)
+		best, bestDist := from, math.Inf(1)
+		for _, to := range pal {
+			th, ts, tl := rgbToHSL(to)
+			if ts < minSaturation {
+				continue
+			}
+			dh := math.Abs(th - target)
+			dh = math.Min(dh, 360-dh) / 180
+			if dist := 2*dh + 1.5*math.Abs(tl-l) + 0.5*math.Abs(ts-s); dist < bestDist {
+				best, bestDist = to, dist
+			}
+		}
+		swap[from] = best
+	}
+	r.swaps[shift] = swap
+	return swap
+}
+
+// rgbToHSL converts the provided color to hue (in degrees), saturation and lightness.
+func rgbToHSL(c color.RGBA) (h, s, l float64) {
+	rf, gf, bf := float64(c.R)/255, float64(c.G)/255, float64(c.B)/255
+	hi, lo := math.Max(rf, math.Max(gf, bf)), math.Min(rf, math.Min(gf, bf))
+	l = (hi + lo) / 2
+	if hi == lo {
+		return 0, 0, l
+	}
+	d := hi - lo
+	if l > 0.5 {
+		s = d / (2 - hi - lo)
+	} else {
+		s = d / (hi + lo)
+	}
+	switch hi {
+	case rf:
+		h = math.Mod((gf-bf)/d+6, 6)
+	case gf:
+		h = (bf-rf)/d + 2
+	default:
+		h = (rf-gf)/d + 4
+	}
+	return h * 60, s, l
+}
+
+// rememberHead keeps track of recent heads; returns false if the head was seen recently.
+func rememberHead(head string) bool {
+	recentHeadsMut.Lock()
+	defer recentHeadsMut.Unlock()
+	if contains(recentHeads, head) {
+		return false
+	}
+	recentHeads = append(recentHeads, head)
+	if len(recentHeads) > recentHeadsMax {
+		recentHeads = recentHeads[1:]
+	}
+	return true
+}
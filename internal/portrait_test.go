@@ -0,0 +1,19 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math/rand"
+	"testing"
+)
+
+func TestRandomPortraitSpec_Seeded(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		spec := randomPortraitSpec(rand.New(rand.NewSource(seed)), nil, nil)
+		assert.EqualValues(t, spec, randomPortraitSpec(rand.New(rand.NewSource(seed)), nil, nil))
+		assert.NotEmpty(t, spec.Head.Image)
+		assert.NotEmpty(t, spec.Body.Image)
+
+		first, second := Resources.ComposePortrait(spec), Resources.ComposePortrait(spec)
+		assert.EqualValues(t, first.Pix, second.Pix, "seed %d composed a different portrait", seed)
+	}
+}
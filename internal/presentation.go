@@ -0,0 +1,173 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/Frabjous-Studios/bankwave/internal/debug"
+	"github.com/hajimehoshi/ebiten/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tags which direct how dialogue is presented. Each can be put on a line as #key:value, or on a node as a key: value
+// header, which applies to every line in the node. Tags on a line win.
+const (
+	TagSpeaker  = "speaker" // TagSpeaker names who's talking; speaker:customer is the customer's name.
+	TagEmotion  = "emotion" // TagEmotion swaps the portrait for a variant; e.g. emotion:angry shows manager_angry.png.
+	TagSound    = "sound"   // TagSound names the blips played as the text crawls, from blipSets; sound:none for quiet.
+	TagPace     = "pace"    // TagPace is slow, fast, or a multiple of the usual crawl speed.
+	TagShake    = "shake"   // TagShake shakes the bubble by a number of pixels; 2 if none is given.
+	TagAuto     = "auto"    // TagAuto moves on after the provided number of seconds.
+	TagLastLine = "lastline"
+)
+
+// blipSets are the sounds which can be played as text crawls, by name.
+var blipSets = map[string][]string{
+	"beep":  {"Computer_Beep_Short-1.ogg", "Computer_Beep_Short-2.ogg"},
+	"click": {"Button_Click.ogg"},
+	"coin":  {"Coin_Drop-1.ogg", "Coin_Drop-2.ogg"},
+	"paper": {"paper-place1.ogg", "paper-place2.ogg", "paper-place3.ogg"},
+}
+
+const defaultShake = 2
+
+// Presentation is how a line of dialogue is shown.
+type Presentation struct {
+	Speaker string
+	Emotion string
+	Blips   []string      // Blips are the sounds played as the text crawls.
+	Pace    float64       // Pace multiplies the crawl speed; 0 is the usual speed.
+	Shake   int           // Shake is how far the bubble shakes, in pixels.
+	Auto    time.Duration // Auto is how long the line is shown before moving on; 0 waits for bubbleDelay.
+	Last    bool          // Last is set for the line shown with the options which follow it.
+	Seen    bool          // Seen is set for lines the player has been shown before; fast-forward skips them.
+	Markup  []Markup      // Markup styles runs of the line's text; see markup.go.
+}
+
+// Apply applies a tag to the presentation. Tags which aren't about presentation are ignored.
+func (p *Presentation) Apply(key, value string) error {
+	value = strings.TrimSpace(value)
+	switch strings.TrimSpace(key) {
+	case TagSpeaker:
+		p.Speaker = value
+	case TagEmotion:
+		p.Emotion = value
+	case TagSound:
+		if value == "none" {
+			p.Blips = nil
+			return nil
+		}
+		blips, ok := blipSets[value]
+		if !ok {
+			return fmt.Errorf("unknown sound %q", value)
+		}
+		p.Blips = blips
+	case TagPace:
+		switch value {
+		case "slow":
+			p.Pace = 0.5
+		case "normal":
+			p.Pace = 0
+		case "fast":
+			p.Pace = 2
+		default:
+			pace, err := strconv.ParseFloat(value, 64)
+			if err != nil || pace <= 0 {
+				return fmt.Errorf("pace must be slow, normal, fast or a positive number; got %q", value)
+			}
+			p.Pace = pace
+		}
+	case TagShake:
+		p.Shake = defaultShake
+		if value != "" {
+			shake, err := strconv.Atoi(value)
+			if err != nil || shake < 0 {
+				return fmt.Errorf("shake must be a number of pixels; got %q", value)
+			}
+			p.Shake = shake
+		}
+	case TagAuto:
+		secs, err := strconv.ParseFloat(value, 64)
+		if err != nil || secs <= 0 {
+			return fmt.Errorf("auto must be a number of seconds; got %q", value)
+		}
+		p.Auto = time.Duration(secs * float64(time.Second))
+	case TagLastLine:
+		p.Last = true
+	}
+	return nil
+}
+
+// ApplyTag applies a line tag, written key:value.
+func (p *Presentation) ApplyTag(tag string) error {
+	key, value, _ := strings.Cut(tag, ":")
+	return p.Apply(key, value)
+}
+
+// SetEmotion swaps the customer's portrait for the variant showing the provided emotion, if there is one; "" or
+// "neutral" swaps back. Simple portraits look for <image>_<emotion>.png, and composed portraits for <head>_<emotion>.png.
+func (c *Customer) SetEmotion(emotion string) {
+	if emotion == c.emotion {
+		return
+	}
+	if c.neutralImg == nil {
+		c.neutralImg = c.Img
+	}
+	c.emotion = emotion
+	c.Img = c.neutralImg
+	if emotion == "" || emotion == "neutral" {
+		return
+	}
+	img, ok := c.variants[emotion]
+	if !ok {
+		img = c.emotionImage(emotion)
+		if c.variants == nil {
+			c.variants = make(map[string]*ebiten.Image)
+		}
+		c.variants[emotion] = img
+	}
+	if img != nil {
+		c.Img = img
+	} else {
+		debug.Printf("no %s variant of portrait %s", emotion, c.ImageKey)
+	}
+}
+
+// QueueEmotion sets the emotion shown by the next call to UpdateEmotion. Safe to call from the dialogue goroutine,
+// while the portrait is being drawn.
+func (c *Customer) QueueEmotion(emotion string) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+	c.nextEmotion = &emotion
+}
+
+// UpdateEmotion shows the emotion last queued, if any.
+func (c *Customer) UpdateEmotion() {
+	c.mut.Lock()
+	next := c.nextEmotion
+	c.nextEmotion = nil
+	c.mut.Unlock()
+	if next != nil {
+		c.SetEmotion(*next)
+	}
+}
+
+func (c *Customer) emotionImage(emotion string) *ebiten.Image {
+	if c.spec == nil {
+		path := emotionVariant(c.ImageKey, emotion)
+		if !Resources.HasImage(path) {
+			return nil
+		}
+		return Resources.GetImage(path)
+	}
+	spec := *c.spec
+	spec.Head.Image = emotionVariant(spec.Head.Image, emotion)
+	if !Resources.HasImage(spec.Head.Image) {
+		return nil
+	}
+	return ebiten.NewImageFromImage(Resources.ComposePortrait(spec))
+}
+
+func emotionVariant(image, emotion string) string {
+	return strings.TrimSuffix(image, ".png") + "_" + emotion + ".png"
+}
@@ -0,0 +1,56 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestPresentation_Apply(t *testing.T) {
+	var p Presentation
+	assert.NoError(t, p.Apply("sound", " beep"))
+	assert.NoError(t, p.Apply("pace", "slow"))
+	assert.NoError(t, p.Apply("portrait", "manager.png")) // not about presentation.
+	for _, tag := range []string{"speaker:customer", "emotion:angry", "shake", "auto:1.5", "lastline", "pace:3"} {
+		assert.NoError(t, p.ApplyTag(tag))
+	}
+	assert.EqualValues(t, Presentation{
+		Speaker: "customer",
+		Emotion: "angry",
+		Blips:   blipSets["beep"],
+		Pace:    3,
+		Shake:   defaultShake,
+		Auto:    1500 * time.Millisecond,
+		Last:    true,
+	}, p)
+
+	assert.NoError(t, p.ApplyTag("sound:none"))
+	assert.Nil(t, p.Blips)
+
+	for _, bad := range []string{"sound:kazoo", "pace:0", "pace:brisk", "shake:lots", "auto:", "auto:-1"} {
+		assert.Error(t, p.ApplyTag(bad), bad)
+	}
+}
+
+func TestEmotionVariant(t *testing.T) {
+	assert.EqualValues(t, "manager_angry.png", emotionVariant("manager.png", "angry"))
+}
+
+func TestCustomer_SetEmotion(t *testing.T) {
+	neutral := Resources.GetImage("manager.png")
+	c := &Customer{BaseSprite: &BaseSprite{Img: neutral}, ImageKey: "manager.png"}
+	c.QueueEmotion("angry")
+	assert.Same(t, neutral, c.Img, "not until the next update")
+
+	c.UpdateEmotion()
+	angry := c.Img
+	assert.Same(t, Resources.GetImage("manager_angry.png"), angry)
+
+	c.SetEmotion("neutral")
+	assert.Same(t, neutral, c.Img)
+	c.SetEmotion("angry")
+	assert.Same(t, angry, c.Img, "variants are cached")
+
+	c.SetEmotion("bored") // there's no such variant.
+	assert.Same(t, neutral, c.Img)
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"image"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Queue is the line of customers waiting in the lobby behind the current customer.
+type Queue struct {
+	*BaseSprite
+
+	walkImages []*ebiten.Image
+	figures    []*queueFigure // figures are the people in line; the front of the line comes first.
+
+	Length  int // Length is the number of customers waiting, which may be more than are shown.
+	Longest int // Longest is the longest the line has been today.
+}
+
+type queueFigure struct {
+	img   *ebiten.Image
+	phase float64
+}
+
+const (
+	QueueFrontX    = 290 // QueueFrontX is where the front of the line stands.
+	QueueSpacing   = 21
+	QueueFloorY    = 104 // QueueFloorY is where everybody's feet are.
+	MaxQueueShown  = 9
+	LongLineLength = 6 // LongLineLength is the number of waiting customers at which the manager starts to complain.
+)
+
+const VarLongestLine = "$longest_line"
+
+func NewQueue() *Queue {
+	return &Queue{
+		BaseSprite: &BaseSprite{},
+		walkImages: []*ebiten.Image{
+			Resources.GetImage("silhouette_walking_1.png"),
+			Resources.GetImage("silhouette_walking_2.png"),
+			Resources.GetImage("silhouette_walking_3.png"),
+			Resources.GetImage("silhouette_walking_4.png"),
+			Resources.GetImage("silhouette_walking_5.png"),
+			Resources.GetImage("silhouette_walking_6.png"),
+		},
+	}
+}
+
+// Resize sets the number of customers waiting; new arrivals join the back of the line, and the front of the line moves
+// up when somebody is called.
+func (q *Queue) Resize(n int) {
+	q.Length = n
+	if n > q.Longest {
+		q.Longest = n
+	}
+	shown := min(n, MaxQueueShown)
+	for len(q.figures) < shown {
+		q.figures = append(q.figures, &queueFigure{img: randSlice(q.walkImages), phase: rand.Float64() * 2 * math.Pi})
+	}
+	if len(q.figures) > shown {
+		q.figures = q.figures[len(q.figures)-shown:]
+	}
+}
+
+// Reset empties the line for a new day.
+func (q *Queue) Reset() {
+	q.figures = nil
+	q.Length = 0
+	q.Longest = 0
+}
+
+// Pressure is how backed up the line is, from 0 (nobody waiting) to 1 (out the door).
+func (q *Queue) Pressure() float64 {
+	return math.Min(1, float64(q.Length)/MaxQueueShown)
+}
+
+func (q *Queue) DrawTo(screen *ebiten.Image) {
+	// the longer the line, the more everybody fidgets.
+	fidget := 1 + 3*q.Pressure()
+	secs := time.Now().Sub(startTime).Seconds()
+	for i := len(q.figures) - 1; i >= 0; i-- { // back of the line is drawn first.
+		f := q.figures[i]
+		bob := math.Abs(math.Sin(secs*fidget+f.phase)) * q.Pressure() * 2
+
+		opts := &ebiten.DrawImageOptions{}
+		opts.GeoM.Translate(float64(QueueFrontX-i*QueueSpacing), QueueFloorY-float64(f.img.Bounds().Dy())-bob)
+		opts.GeoM.Scale(ScaleFactor, ScaleFactor)
+		opts.ColorScale.ScaleAlpha(0.85)
+		screen.DrawImage(f.img, opts)
+	}
+}
+
+func (q *Queue) Bounds() image.Rectangle {
+	return rect(QueueFrontX-MaxQueueShown*QueueSpacing, QueueFloorY-60, MaxQueueShown*QueueSpacing, 60)
+}
@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"github.com/Frabjous-Studios/bankwave/internal/debug"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// Treatment is how a customer was treated on a visit to the bank.
+type Treatment string
+
+const (
+	TreatedFairly     Treatment = "fair"
+	TreatedShorted    Treatment = "shorted"     // TreatedShorted means the customer left with less cash than they asked for.
+	TreatedOverpaid   Treatment = "overpaid"    // TreatedOverpaid means the customer left with more cash than they asked for.
+	TreatedRefused    Treatment = "refused"     // TreatedRefused means the customer's slip was handed back to them.
+	TreatedStormedOff Treatment = "stormed_off" // TreatedStormedOff means the customer got tired of waiting and left.
+)
+
+// attitude is how much each treatment changes a regular's attitude toward the player.
+func (t Treatment) attitude() int {
+	switch t {
+	case TreatedFairly:
+		return 1
+	case TreatedOverpaid:
+		return 2
+	case TreatedRefused:
+		return -1
+	case TreatedShorted, TreatedStormedOff:
+		return -2
+	}
+	return 0
+}
+
+// Visit is a single visit a regular made to the bank.
+type Visit struct {
+	Day       int
+	Treatment Treatment
+}
+
+// Regular is a customer who keeps coming back; they remember how they were treated.
+type Regular struct {
+	Name    string
+	Seed    int64 // Seed generates the regular's portrait.
+	AcctNum int
+	Balance int // Balance is the balance of their checking account, carried over from day to day.
+	Visits  []Visit
+}
+
+// Attitude is how the regular feels about the player, from -5 (furious) to 5 (adoring).
+func (r *Regular) Attitude() int {
+	var result int
+	for _, v := range r.Visits {
+		result += v.Treatment.attitude()
+	}
+	return clamp(result, -5, 5)
+}
+
+// LastTreatment is how the regular was treated on their last visit; empty if this is their first.
+func (r *Regular) LastTreatment() Treatment {
+	if len(r.Visits) == 0 {
+		return ""
+	}
+	return r.Visits[len(r.Visits)-1].Treatment
+}
+
+const RosterSize = 6
+
+// RegularChance is the chance a random customer is one of the regulars.
+const RegularChance = 0.35
+
+const (
+	VarIsRegular       = "$is_regular"
+	VarTimesMet        = "$times_met"
+	VarLastTreatment   = "$last_treatment"
+	VarRegularAttitude = "$regular_attitude"
+)
+
+func newRoster() []*Regular {
+	result := make([]*Regular, RosterSize)
+	for i := range result {
+		result[i] = &Regular{
+			Name:    randomFullName(),
+			Seed:    rand.Int63(),
+			AcctNum: randomAcctNumber(),
+			Balance: randomAccountValue(),
+		}
+	}
+	return result
+}
+
+// maybeRegular sometimes swaps a random customer for one of the regulars, and tells Yarn all about them.
+func (m *MainScene) maybeRegular() {
+	c := m.Customer
+	var reg *Regular
+	if strings.HasPrefix(m.CurrNode, "Random") && m.Runner.PortraitID(m.CurrNode) == "random" && rand.Float64() < RegularChance {
+		reg = randSlice(m.regulars)
+		portrait := newSeededPortrait(reg.Seed, nil, nil)
+		c.Img, c.ImageKey, c.spec = portrait.Img, portrait.ImageKey, portrait.spec
+		c.CustomerName = reg.Name
+		c.Regular = reg
+		c.Mood = Mood(clamp(int(c.Mood)+reg.Attitude()/3, int(MoodFurious), int(MoodDelighted))) // grudges last.
+		m.Runner.SetFullName(reg.Name)
+		debug.Printf("regular %s is back; visit %d", reg.Name, len(reg.Visits)+1)
+	}
+	if reg == nil {
+		m.Runner.SetVar(VarIsRegular, false)
+		m.Runner.SetVar(VarTimesMet, float32(0))
+		m.Runner.SetVar(VarLastTreatment, "")
+		m.Runner.SetVar(VarRegularAttitude, float32(0))
+		return
+	}
+	m.Runner.SetVar(VarIsRegular, len(reg.Visits) > 0) // on their first visit, they're just another customer.
+	m.Runner.SetVar(VarTimesMet, float32(len(reg.Visits)+1))
+	m.Runner.SetVar(VarLastTreatment, string(reg.LastTreatment()))
+	m.Runner.SetVar(VarRegularAttitude, float32(reg.Attitude()))
+}
+
+// Treatment sums up how the customer was treated on this visit.
+func (c *Customer) Treatment() Treatment {
+	switch {
+	case c.Patience <= 0:
+		return TreatedStormedOff
+	case c.Refused:
+		return TreatedRefused
+	case c.Overpaid:
+		return TreatedOverpaid
+	case c.CustomerIntent == IntentWithdraw && c.DepositSlip != nil && c.CashInHand < c.DepositSlip.Value:
+		return TreatedShorted
+	case c.CustomerIntent == IntentWithdraw && c.DepositSlip != nil && c.CashInHand > c.DepositSlip.Value:
+		return TreatedOverpaid
+	}
+	return TreatedFairly
+}
+
+// recordVisit remembers how a departing regular was treated.
+func (m *MainScene) recordVisit() {
+	if m.Customer == nil || m.Customer.Regular == nil {
+		return
+	}
+	t := m.Customer.Treatment()
+	m.Customer.Regular.Visits = append(m.Customer.Regular.Visits, Visit{Day: m.dayIdx, Treatment: t})
+	debug.Printf("regular %s was treated: %s", m.Customer.CustomerName, t)
+}
+
+// regularAccounts opens each regular's account on the provided day, with whatever they had left yesterday.
+func (m *MainScene) regularAccounts(day *Day) {
+	for _, reg := range m.regulars {
+		num := strconv.Itoa(reg.AcctNum)
+		day.Accounts[num] = &Account{Owner: reg.Name, Number: num, Checking: reg.Balance, StartBalance: reg.Balance}
+	}
+}
+
+// saveRegularBalances carries the regulars' balances over to tomorrow.
+func (m *MainScene) saveRegularBalances() {
+	for _, reg := range m.regulars {
+		if acct, ok := m.Day.Accounts[strconv.Itoa(reg.AcctNum)]; ok {
+			reg.Balance = acct.Checking
+		}
+	}
+}
@@ -26,45 +26,6 @@ import (
 	"strings"
 )
 
-var bodies = []string{
-	"body_armor.png",
-	"body_cloak.png",
-	"body_jumpsuit.png",
-	"body_sleeveless.png",
-	"body_sleeveless_alt.png",
-	"body_suit.png",
-	"body_tshirt.png",
-	"body_tshirt_2tone.png",
-	"body_tshirt_alt.png",
-	"body_tshirt_alt2.png",
-	"body_wifebeat.png",
-}
-
-var heads = []string{
-	"head_pinkvirus.png",
-	"head_3eyeShades.png",
-	"head_antlerClops.png",
-	"head_apeMojo.png",
-	"head_blank.png",
-	"head_boomerang.png",
-	"head_bulbous.png",
-	"head_bunGirl.png",
-	"head_cactus.png",
-	"head_cat.png",
-	"head_eraserGlasses.png",
-	"head_gills.png",
-	"head_glareGaunt.png",
-	"head_gorgeous.png",
-	"head_grimFlattop.png",
-	"head_insect.png",
-	"head_logBirb.png",
-	"head_mohawkShades.png",
-	"head_pillBot.png",
-	"head_ponytails.png",
-	"head_psychoClown.png",
-	"head_smileScreen.png",
-}
-
 // Resources makes all multimedia resources for the game available.
 var Resources = resources{}
 
@@ -74,8 +35,10 @@ type resources struct {
 	nineSlices map[string]*image.NineSlice
 	images     map[string]*ebiten.Image
 	shaders    map[string]*ebiten.Shader
-	heads      map[string]*ebiten.Image
-	bodies     map[string]*ebiten.Image
+	parts      PartsManifest
+	cpuImages  map[string]*img2.RGBA // cpuImages are decoded images used to compose portraits.
+	palette    []color.RGBA
+	swaps      map[int]map[color.RGBA]color.RGBA
 	lists      map[string][]string
 	players    map[string]*audio.Player
 	music      map[string]*audio.InfiniteLoop
@@ -112,8 +75,8 @@ func init() {
 
 	// images
 	Resources.images = make(map[string]*ebiten.Image)
-	Resources.bodies = Resources.loadImages(bodies)
-	Resources.heads = Resources.loadImages(heads)
+	Resources.parts = Resources.loadManifest(partsManifest)
+	Resources.loadImages(Resources.parts.Images())
 
 	// load nineslices
 	Resources.nineSlices = make(map[string]*image.NineSlice)
@@ -158,6 +121,7 @@ func init() {
 	Resources.images["deposit_slip_empty"] = Resources.GetImage("deposit_slip.png")
 	Resources.images["deposit_slip_deposit"] = Resources.GetImage("deposit_slip_deposit.png")
 	Resources.images["deposit_slip_withdrawal"] = Resources.GetImage("deposit_slip_withdrawal.png")
+	Resources.images["deposit_slip_both"] = overlayDiff("deposit_slip_deposit.png", "deposit_slip_withdrawal.png", "deposit_slip.png")
 
 	Resources.images["photo_id"] = Resources.GetImage("photo_id.png")
 
@@ -245,11 +209,7 @@ func (r *resources) GetMusic(aCtx *audio.Context, file string) *audio.InfiniteLo
 
 // Portrait composites the provided head and body portraits on the CPU into a new image, which is returned.
 func (r *resources) Portrait(head, body string) img2.Image {
-	rect := rect(0, 0, 100, 100)
-	out := img2.NewRGBA(rect)
-	draw.Draw(out, rect, r.GetImage(body), img2.Pt(0, -33), draw.Over)
-	draw.Draw(out, rect, r.GetImage(head), img2.Pt(0, 0), draw.Over)
-	return out
+	return r.ComposePortrait(PortraitSpec{Head: PortraitLayer{Image: head}, Body: PortraitLayer{Image: body}})
 }
 
 func (r *resources) GetAnim(path string) *asebiten.Animation {
@@ -273,11 +233,23 @@ func (r *resources) GetNineSlice(id string) *image.NineSlice {
 	return r.nineSlices[id]
 }
 
-// loadImages loads a separate map of special images, given the provided paths.
-func (r *resources) loadImages(paths []string) map[string]*ebiten.Image {
-	result := make(map[string]*ebiten.Image)
+// loadImages preloads the images at the provided paths for compositing portraits.
+func (r *resources) loadImages(paths []string) {
 	for _, path := range paths {
-		result[path] = r.GetImage(path)
+		r.decoded(path)
+	}
+}
+
+// loadManifest loads the portrait parts manifest at the provided path.
+func (r *resources) loadManifest(path string) PartsManifest {
+	f, err := art.Open(fmt.Sprintf("gamedata/img/%s", path))
+	if err != nil {
+		panic(fmt.Errorf("unable to open parts manifest: %v", err))
+	}
+	defer f.Close()
+	result, err := parseManifest(f)
+	if err != nil {
+		panic(fmt.Errorf("unable to parse parts manifest: %v", err))
 	}
 	return result
 }
@@ -310,6 +282,48 @@ func (r *resources) GetImage(path string) *ebiten.Image {
 	return r.images[path]
 }
 
+// HasImage returns true if there's an image at the provided path.
+func (r *resources) HasImage(path string) bool {
+	_, err := fs.Stat(art, fmt.Sprintf("gamedata/img/%s", path))
+	return err == nil
+}
+
+// decodeImage decodes the provided image on the CPU, for compositing before it's sent to the GPU.
+func decodeImage(path string) (img2.Image, error) {
+	f, err := art.Open(fmt.Sprintf("gamedata/img/%s", path))
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	img, _, err := img2.Decode(f)
+	return img, err
+}
+
+// overlayDiff composites every pixel in over which differs from base onto a copy of dst. Used to combine the marks made
+// on different copies of the same form.
+func overlayDiff(dst, over, base string) *ebiten.Image {
+	var imgs [3]img2.Image
+	for idx, path := range []string{dst, over, base} {
+		img, err := decodeImage(path)
+		if err != nil {
+			debug.Printf("failed to decode image: %s: %v", path, err)
+			return Resources.GetImage(dst)
+		}
+		imgs[idx] = img
+	}
+	bounds := imgs[0].Bounds()
+	out := img2.NewRGBA(bounds)
+	draw.Draw(out, bounds, imgs[0], bounds.Min, draw.Src)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if imgs[1].At(x, y) != imgs[2].At(x, y) {
+				out.Set(x, y, imgs[1].At(x, y))
+			}
+		}
+	}
+	return ebiten.NewImageFromImage(out)
+}
+
 const dpi = 72
 
 // GetFace returns a new font face with the provided FontID and size.
@@ -402,6 +416,7 @@ func newPortrait(body, head string) *Customer {
 
 	return &Customer{
 		ImageKey: fmt.Sprintf("%s:%s", body, head),
+		spec:     &PortraitSpec{Head: PortraitLayer{Image: head}, Body: PortraitLayer{Image: body}},
 		BaseSprite: &BaseSprite{
 			Img: img,
 			X:   portraitStartX,
@@ -410,8 +425,17 @@ func newPortrait(body, head string) *Customer {
 	}
 }
 
-func newRandPortrait() *Customer {
-	return newPortrait(randMapKey(Resources.bodies), randMapKey(Resources.heads))
+// newRandPortrait creates a random portrait from parts with all the required tags, preferring parts with the preferred
+// tags.
+func newRandPortrait(required, preferred []string) *Customer {
+	var spec PortraitSpec
+	for i := 0; i < recentHeadsMax; i++ { // try not to repeat the same heads over and over.
+		spec = randomPortraitSpec(rand.New(rand.NewSource(rand.Int63())), required, preferred)
+		if rememberHead(spec.Head.Image) {
+			break
+		}
+	}
+	return newComposedPortrait(spec)
 }
 
 func newSimplePortrait(head string) *Customer {
@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"fmt"
+	"github.com/Frabjous-Studios/bankwave/internal/debug"
+	"math/rand"
+)
+
+// RobberyOutcome describes how a robbery turned out.
+type RobberyOutcome string
+
+const (
+	RobberyUnresolved RobberyOutcome = ""
+	RobberyGotAway    RobberyOutcome = "got_away"  // RobberyGotAway means no alarm was pressed and the robber left with the cash.
+	RobberyPolice     RobberyOutcome = "police"    // RobberyPolice means the silent alarm was pressed; police are on their way.
+	RobberyCaught     RobberyOutcome = "caught"    // RobberyCaught means the police showed up and caught the robber.
+	RobberyFled       RobberyOutcome = "fled"      // RobberyFled means the loud alarm scared the robber off empty-handed.
+	RobberyEscalated  RobberyOutcome = "escalated" // RobberyEscalated means the robber didn't take kindly to the situation.
+)
+
+// Robbery is a hold-up at the teller window.
+type Robbery struct {
+	Demand  int       // Demand is the amount of cash the robber wants, in cents.
+	Handed  int       // Handed is the amount of cash handed over to the robber, in cents.
+	Alarm   AlarmMode // Alarm is the first alarm pressed during the robbery.
+	Outcome RobberyOutcome
+	Loot    []Sprite // Loot is the cash handed over; it goes back in the till if it's recovered.
+}
+
+// Taken is the amount of cash the robber made off with, in cents.
+func (r *Robbery) Taken() int {
+	switch r.Outcome {
+	case RobberyFled, RobberyCaught:
+		return 0
+	}
+	return r.Handed
+}
+
+// PoliceDelay is the number of customers who come and go before the police respond to the silent alarm.
+const PoliceDelay = 2
+
+// RobberFleeChance is the chance the loud alarm scares the robber off; otherwise things get ugly.
+const RobberFleeChance = 0.6
+
+const (
+	VarRobberyDemand  = "$robbery_demand"
+	VarRobberyOutcome = "$robbery_outcome"
+	VarRobberyTaken   = "$robbery_taken"
+	VarRobbersCaught  = "$robbers_caught"
+	VarFalseAlarms    = "$false_alarms"
+)
+
+// startRobbery starts a robbery; the robber demands the provided number of dollars.
+func (m *MainScene) startRobbery(amt int) error {
+	m.robbery = &Robbery{Demand: amt * 100}
+	m.Day.Robberies = append(m.Day.Robberies, m.robbery)
+	m.till.Robberies = append(m.till.Robberies, m.robbery)
+	m.Runner.SetVar(VarRobberyDemand, float32(amt))
+	return nil
+}
+
+// resolveRobbery decides how the current robbery ends, based on the alarm pressed and the cash handed over.
+func (m *MainScene) resolveRobbery() error {
+	r := m.robbery
+	if r == nil {
+		return fmt.Errorf("call to resolve_robbery with no robbery in progress")
+	}
+	switch r.Alarm {
+	case AlarmModeLeft:
+		r.Outcome = RobberyPolice
+		m.policeIn = PoliceDelay
+		m.policeFor = r
+	case AlarmModeRight:
+		if rand.Float64() < RobberFleeChance {
+			r.Outcome = RobberyFled
+			m.recoverLoot(r) // they drop it on the way out.
+		} else {
+			r.Outcome = RobberyEscalated
+		}
+	default:
+		if r.Handed >= r.Demand {
+			r.Outcome = RobberyGotAway
+		} else {
+			r.Outcome = RobberyEscalated
+		}
+	}
+	debug.Printf("robbery resolved: %s; taken %s", r.Outcome, fmtCents(r.Taken()))
+	m.Runner.SetVar(VarRobberyOutcome, string(r.Outcome))
+	m.Runner.SetVar(VarRobberyTaken, float32(r.Taken())/100)
+	m.robbery = nil
+	return nil
+}
+
+// abandonRobbery resolves the current robbery, if the robber left before their dialogue got around to it.
+func (m *MainScene) abandonRobbery() {
+	if m.robbery == nil {
+		return
+	}
+	debug.Println("robber left before the robbery was resolved")
+	_ = m.resolveRobbery()
+}
+
+// handToRobber hands the held cash over to the robber.
+func (m *MainScene) handToRobber() {
+	for _, held := range m.holding {
+		switch s := held.(type) {
+		case *Money:
+			m.robbery.Handed += s.Value
+		case *Stack:
+			m.robbery.Handed += s.Count * s.Value * 100
+		default:
+			continue
+		}
+		m.robbery.Loot = append(m.robbery.Loot, held)
+		m.removeSprite(held)
+	}
+	m.holding = nil
+	m.playCashFlip()
+}
+
+// soundAlarm handles the player pressing one of the alarm buttons. Left is the silent alarm, which calls the police;
+// right is the loud alarm, which sounds the siren right away. Pressing either with no robbery in progress is a false
+// alarm.
+func (m *MainScene) soundAlarm(mode AlarmMode) {
+	if mode == AlarmModeRight {
+		m.playPolice("Police-2.ogg")
+	}
+	if m.robbery != nil {
+		if m.robbery.Alarm == AlarmModeUnpressed {
+			m.robbery.Alarm = mode
+		}
+		return
+	}
+	if m.policeIn > 0 {
+		return // they're already on their way.
+	}
+	m.Day.FalseAlarms++
+	m.Runner.SetVar(VarFalseAlarms, float32(m.Day.FalseAlarms))
+	if mode == AlarmModeLeft {
+		m.policeIn = PoliceDelay
+		m.policeFor = nil
+	} else {
+		m.falseAlarmMemo()
+	}
+}
+
+// policeTick counts down to the arrival of the police, if they've been called. It's called for every new customer.
+func (m *MainScene) policeTick() {
+	if m.policeIn == 0 {
+		return
+	}
+	m.policeIn--
+	if m.policeIn > 0 {
+		return
+	}
+	m.playPolice("Police-1.ogg")
+	if m.policeFor == nil {
+		m.falseAlarmMemo()
+		return
+	}
+	m.policeFor.Outcome = RobberyCaught
+	m.recoverLoot(m.policeFor)
+	m.policeFor = nil
+	m.Day.RobbersCaught++
+	m.Runner.SetVar(VarRobbersCaught, float32(m.Day.RobbersCaught))
+	m.terminal.Deliver(&Memo{From: T("memo.from_police"), Text: T("memo.robber_caught")})
+}
+
+// recoverLoot puts the cash handed to a robber back in the till.
+func (m *MainScene) recoverLoot(r *Robbery) {
+	for _, s := range r.Loot {
+		for _, money := range m.till.Return(s) {
+			m.Sprites = append(m.Sprites, money)
+		}
+	}
+	r.Loot = nil
+}
+
+func (m *MainScene) falseAlarmMemo() {
+	m.terminal.Deliver(&Memo{From: T("memo.from_police"), Text: T("memo.false_alarm")})
+}
+
+func (m *MainScene) playPolice(name string) {
+	snd := Resources.GetSound(m.Game.ACtx, name)
+	snd.Rewind()
+	snd.Play()
+}
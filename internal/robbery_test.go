@@ -0,0 +1,31 @@
+package internal
+
+import (
+	"github.com/DrJosh9000/yarn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMainScene_AbandonRobbery(t *testing.T) {
+	runner, err := NewDialogueRunner(make(yarn.MapVariableStorage), nil, nil)
+	require.NoError(t, err)
+	m := &MainScene{Runner: runner, Day: &Day{}, till: NewTill()}
+	require.NoError(t, m.startRobbery(100))
+
+	m.abandonRobbery() // the robber was sent away before resolve_robbery.
+	assert.Nil(t, m.robbery)
+	assert.EqualValues(t, RobberyEscalated, m.Day.Robberies[0].Outcome)
+
+	// the next customer gets their cash, rather than the robber.
+	bill := &Money{BaseSprite: &BaseSprite{}, Value: 2000}
+	m.Customer = &Customer{CustomerIntent: IntentWithdraw, DepositSlip: &DepositSlip{Value: 5000, IsWithdrawal: true}}
+	m.Sprites = []Sprite{bill}
+	m.holding = []Sprite{bill}
+	m.customerDrop()
+	assert.EqualValues(t, 2000, m.Customer.CashInHand)
+	assert.Zero(t, m.Day.Robberies[0].Handed)
+
+	m.soundAlarm(AlarmModeLeft)
+	assert.EqualValues(t, 1, m.Day.FalseAlarms)
+}
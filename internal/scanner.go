@@ -0,0 +1,199 @@
+package internal
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// ScanTimeCost is the amount of time taken off the day clock whenever the player scans something.
+const ScanTimeCost = 5 * time.Second
+
+// GameStartDate is the in-game date of the first day on the job.
+var GameStartDate = time.Date(2088, time.May, 1, 0, 0, 0, 0, time.UTC)
+
+const dateFormat = "01/02/06"
+
+// PhotoID is a customer's identification card.
+type PhotoID struct {
+	*BaseSprite
+	Name    string
+	Expires time.Time
+}
+
+const IDExpiredChance = 0.15
+const IDWrongNameChance = 0.1
+
+// newPhotoID creates an ID card for the current customer on the provided date. Some IDs are expired, and some belong
+// to somebody else entirely.
+func (m *MainScene) newPhotoID(today time.Time) *PhotoID {
+	front := ebiten.NewImage(55, 33)
+	front.DrawImage(Resources.GetImage("photo_id"), nil)
+
+	pos := m.randomCounterPos()
+	id := &PhotoID{
+		BaseSprite: &BaseSprite{Img: front, X: pos.X, Y: pos.Y},
+		Name:       m.Customer.CustomerName,
+		Expires:    today.AddDate(rand.Intn(5)+1, rand.Intn(12), 0),
+	}
+	if rand.Float64() < IDExpiredChance {
+		id.Expires = today.AddDate(0, 0, -(rand.Intn(700) + 1))
+	}
+	if rand.Float64() < IDWrongNameChance {
+		id.Name = randomFullName()
+	}
+
+	// shrink the customer's portrait into the photo box.
+	opts := &ebiten.DrawImageOptions{}
+	opts.GeoM.Scale(0.11, 0.11)
+	opts.GeoM.Translate(4, 2)
+	front.DrawImage(m.Customer.Img, opts)
+
+	m.txt.SetColor(depositSlipColor)
+	m.txt.SetFont(Resources.GetFont(DialogFont))
+	m.txt.SetSizePx(8)
+	m.txt.SetTarget(front)
+	m.txt.Draw(strings.ToUpper(id.Name), 3, 13)
+	m.txt.Draw(T("id.expires", id.Expires.Format(dateFormat)), 3, 20)
+	return id
+}
+
+// today is the in-game date.
+func (m *MainScene) today() time.Time {
+	return GameStartDate.AddDate(0, 0, m.dayIdx)
+}
+
+// randomRoutingNumber generates a 9-digit routing number; valid ones pass the ABA checksum.
+func randomRoutingNumber(valid bool) string {
+	digits := make([]int, 9)
+	for i := 0; i < 8; i++ {
+		digits[i] = rand.Intn(10)
+	}
+	digits[8] = (10 - routingSum(digits[:8])%10) % 10
+	if !valid {
+		digits[8] = (digits[8] + rand.Intn(9) + 1) % 10
+	}
+	var sb strings.Builder
+	for _, d := range digits {
+		sb.WriteByte(byte('0' + d))
+	}
+	return sb.String()
+}
+
+// routingValid checks the ABA checksum of the provided routing number.
+func routingValid(routing string) bool {
+	if len(routing) != 9 {
+		return false
+	}
+	digits := make([]int, 9)
+	for i, r := range routing {
+		if r < '0' || r > '9' {
+			return false
+		}
+		digits[i] = int(r - '0')
+	}
+	return routingSum(digits)%10 == 0
+}
+
+func routingSum(d []int) int {
+	weights := []int{3, 7, 1}
+	var sum int
+	for i, v := range d {
+		sum += weights[i%3] * v
+	}
+	return sum
+}
+
+// Scan prints a detailed readout for the provided document on the terminal. Returns false if the sprite can't be
+// scanned.
+func (t *Terminal) Scan(s Sprite) bool {
+	t.reading = nil
+	t.listing = false
+	t.lines = nil
+	switch s := s.(type) {
+	case *DepositSlip:
+		t.scanSlip(s)
+	case *Check:
+		t.scanCheck(s)
+	case *PhotoID:
+		t.scanID(s)
+	case *Money:
+		t.scanMoney(s)
+	case *Stack:
+		t.Print(T("scanner.bundle"), T("scanner.bundle_count", s.Count, s.Value, fmtCents(s.Count*s.Value*100)), T("scanner.band_intact"))
+	default:
+		t.Print(T("scanner.unreadable"))
+		return false
+	}
+	return true
+}
+
+func (t *Terminal) scanSlip(s *DepositSlip) {
+	kind := T("scanner.slip_blank")
+	switch {
+	case s.ForDeposit && s.ForWithdrawal:
+		kind = T("scanner.slip_both")
+	case s.ForDeposit:
+		kind = T("scanner.slip_deposit")
+	case s.ForWithdrawal:
+		kind = T("scanner.slip_withdrawal")
+	}
+	t.Print(T("scanner.slip", kind))
+	if acct, ok := t.scene.Day.Accounts[s.WrittenAcct]; ok {
+		t.Print(T("scanner.acct_ok", s.WrittenAcct), T("scanner.owner", acct.Owner))
+	} else {
+		t.Print(T("scanner.acct_not_found", s.WrittenAcct))
+	}
+	if s.Signature == "" {
+		t.Print(T("scanner.unsigned"))
+	} else {
+		t.Print(T("scanner.signed", s.Signature))
+	}
+	if s.Itemized {
+		t.Print(T("scanner.sum", fmtCents(s.Cash+s.ChecksTotal()), fmtCents(s.Value)))
+	} else {
+		t.Print(T("scanner.amount", fmtCents(s.Value)))
+	}
+}
+
+func (t *Terminal) scanCheck(c *Check) {
+	t.Print(T("scanner.check"), T("scanner.routing", c.Routing))
+	if routingValid(c.Routing) && c.Valid {
+		t.Print(T("scanner.check_valid"))
+	} else {
+		t.Print(T("scanner.check_invalid"))
+	}
+	t.Print(T("scanner.amount", fmtCents(c.Value)))
+	if !c.Signed {
+		t.Print(T("scanner.check_unsigned"))
+	}
+}
+
+func (t *Terminal) scanID(id *PhotoID) {
+	t.Print(T("scanner.id"), T("scanner.name", id.Name))
+	if id.Expires.Before(t.scene.today()) {
+		t.Print(T("scanner.expired", id.Expires.Format(dateFormat)))
+	} else {
+		t.Print(T("scanner.valid_thru", id.Expires.Format(dateFormat)))
+	}
+	var accts []string
+	for num, acct := range t.scene.Day.Accounts {
+		if acct.Owner == id.Name {
+			accts = append(accts, num)
+		}
+	}
+	if len(accts) == 0 {
+		t.Print(T("scanner.no_accounts"))
+	} else {
+		t.Print(T("scanner.accounts", strings.Join(accts, " ")))
+	}
+}
+
+func (t *Terminal) scanMoney(m *Money) {
+	if m.IsCoin {
+		t.Print(T("scanner.token"), T("scanner.token_value", m.Value), T("scanner.alloy_ok"))
+		return
+	}
+	t.Print(T("scanner.scrip"), T("scanner.scrip_value", fmtDollars(m.Value)), T("scanner.genuine"))
+}
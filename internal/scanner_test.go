@@ -0,0 +1,17 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRoutingValid(t *testing.T) {
+	assert.True(t, routingValid("011000015")) // federal reserve, boston
+	assert.False(t, routingValid("011000016"))
+	assert.False(t, routingValid("01100001"))
+
+	for i := 0; i < 100; i++ {
+		assert.True(t, routingValid(randomRoutingNumber(true)))
+		assert.False(t, routingValid(randomRoutingNumber(false)))
+	}
+}
@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"encoding/json"
+	"github.com/Frabjous-Studios/bankwave/internal/debug"
+	"os"
+	"path/filepath"
+)
+
+// Settings are the player's choices which are kept between games.
+type Settings struct {
+	Locale string `json:"locale"` // Locale is the code of the language the game is played in.
+}
+
+// configDir is where the game keeps files for the player, like their settings.
+func configDir() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "bankwave"), nil
+}
+
+// settingsPath is where settings are saved.
+func settingsPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "settings.json"), nil
+}
+
+// LoadSettings reads the player's saved settings, or the defaults if there aren't any.
+func LoadSettings() Settings {
+	result := Settings{Locale: DefaultLocale}
+	path, err := settingsPath()
+	if err != nil {
+		debug.Printf("no config directory: %v", err)
+		return result
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			debug.Printf("error reading settings: %v", err)
+		}
+		return result
+	}
+	if err := json.Unmarshal(b, &result); err != nil {
+		debug.Printf("error parsing settings %s: %v", path, err)
+	}
+	return result
+}
+
+// Save writes the settings where LoadSettings will find them.
+func (s Settings) Save() error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+// Apply puts the settings into effect.
+func (s Settings) Apply() {
+	if err := SetLocale(s.Locale); err != nil {
+		debug.Printf("error setting locale %s: %v", s.Locale, err)
+	}
+}
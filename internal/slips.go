@@ -0,0 +1,138 @@
+package internal
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// SlipError is a concrete, visible mistake a customer made when filling out their deposit slip.
+type SlipError uint8
+
+const (
+	SlipErrorNone        SlipError = iota
+	SlipErrorAcctDigits            // SlipErrorAcctDigits means the account number written on the slip has wrong digits.
+	SlipErrorAmount                // SlipErrorAmount means the amount written differs from the cash on the counter.
+	SlipErrorBothBoxes             // SlipErrorBothBoxes means both the deposit and withdrawal boxes were checked.
+	SlipErrorNoSignature           // SlipErrorNoSignature means the customer forgot to sign.
+	SlipErrorWrongName             // SlipErrorWrongName means the slip is signed with somebody else's name.
+	SlipErrorTotal                 // SlipErrorTotal means the lines on an itemized slip don't add up to its total.
+)
+
+func (e SlipError) String() string {
+	switch e {
+	case SlipErrorNone:
+		return "none"
+	case SlipErrorAcctDigits:
+		return "account number"
+	case SlipErrorAmount:
+		return "wrong amount"
+	case SlipErrorBothBoxes:
+		return "both boxes"
+	case SlipErrorNoSignature:
+		return "no signature"
+	case SlipErrorWrongName:
+		return "wrong name"
+	case SlipErrorTotal:
+		return "bad total"
+	default:
+		return fmt.Sprintf("SlipError(%d)", e)
+	}
+}
+
+// Describe is how the error is shown to the player, in the current locale.
+func (e SlipError) Describe() string {
+	return T("slip.error." + strings.ReplaceAll(e.String(), " ", "_"))
+}
+
+// SlipErrorChance is the chance a filled-out slip has some mistake on it.
+const SlipErrorChance = 0.2
+
+// maybeError returns SlipErrorNone most of the time, or a random error with probability SlipErrorChance.
+func (s *DepositSlip) maybeError() SlipError {
+	if rand.Float64() >= SlipErrorChance {
+		return SlipErrorNone
+	}
+	return s.randomError()
+}
+
+// randomError picks one of the errors which could be made on this slip at random. Only deposits carry cash, so only
+// deposits can have the wrong amount, and only itemized slips have a total to get wrong.
+func (s *DepositSlip) randomError() SlipError {
+	options := []SlipError{SlipErrorAcctDigits, SlipErrorBothBoxes, SlipErrorNoSignature, SlipErrorWrongName}
+	if s.ForDeposit {
+		options = append(options, SlipErrorAmount)
+	}
+	if s.Itemized {
+		options = append(options, SlipErrorTotal)
+	}
+	return randSlice(options)
+}
+
+// CashTotal is the amount of cash this slip claims to be depositing or withdrawing.
+func (s *DepositSlip) CashTotal() int {
+	if s.Itemized {
+		return s.Cash
+	}
+	return s.Value
+}
+
+// ChecksTotal is the sum of all checks listed on this slip.
+func (s *DepositSlip) ChecksTotal() int {
+	var result int
+	for _, c := range s.Checks {
+		result += c
+	}
+	return result
+}
+
+// applyError alters what's written on the slip so the provided error is visible to a careful teller.
+func (s *DepositSlip) applyError(err SlipError) {
+	s.Error = err
+	s.IsWrong = err != SlipErrorNone
+	switch err {
+	case SlipErrorAcctDigits:
+		s.WrittenAcct = mangleAcctNum(s.AcctNum)
+	case SlipErrorAmount:
+		if s.Itemized {
+			s.Cash = mangleAmount(s.CashValue)
+			s.Value = s.Cash + s.ChecksTotal()
+		} else {
+			s.Value = mangleAmount(s.CashValue)
+		}
+	case SlipErrorTotal:
+		s.Value = mangleAmount(s.Cash + s.ChecksTotal())
+	case SlipErrorBothBoxes:
+		s.ForDeposit = true
+		s.ForWithdrawal = true
+	case SlipErrorNoSignature:
+		s.Signature = ""
+	case SlipErrorWrongName:
+		s.Signature = randomFullName()
+	}
+}
+
+// mangleAcctNum either drops, duplicates, or changes a digit of the provided account number.
+func mangleAcctNum(acctNum int) string {
+	digits := []byte(strconv.Itoa(acctNum))
+	idx := rand.Intn(len(digits))
+	switch rand.Intn(3) {
+	case 0: // dropped a digit
+		return string(digits[:idx]) + string(digits[idx+1:])
+	case 1: // doubled a digit
+		return string(digits[:idx+1]) + string(digits[idx:])
+	default: // changed a digit
+		digits[idx] = '0' + byte((int(digits[idx]-'0')+rand.Intn(9)+1)%10)
+	}
+	return string(digits)
+}
+
+// mangleAmount returns an amount which is off from the provided value by a believable number of dollars.
+func mangleAmount(val int) int {
+	delta := (rand.Intn(50) + 1) * 100
+	if val-delta > 0 && rand.Float64() < 0.5 {
+		return val - delta
+	}
+	return val + delta
+}
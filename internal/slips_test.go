@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strconv"
+	"testing"
+)
+
+func TestMangleAcctNum(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		acct := randomAcctNumber()
+		assert.NotEqual(t, strconv.Itoa(acct), mangleAcctNum(acct))
+	}
+}
+
+func TestDepositSlip_ApplyError(t *testing.T) {
+	tests := []struct {
+		err      SlipError
+		itemized bool
+		mangled  func(s *DepositSlip) bool // mangled checks the field the error should have changed.
+	}{
+		{SlipErrorAcctDigits, false, func(s *DepositSlip) bool { return s.WrittenAcct != "12345" }},
+		{SlipErrorAmount, false, func(s *DepositSlip) bool { return s.Value != s.CashValue }},
+		{SlipErrorAmount, true, func(s *DepositSlip) bool { return s.Cash != s.CashValue && s.Value == s.Cash+s.ChecksTotal() }},
+		{SlipErrorBothBoxes, false, func(s *DepositSlip) bool { return s.ForDeposit && s.ForWithdrawal }},
+		{SlipErrorNoSignature, false, func(s *DepositSlip) bool { return s.Signature == "" }},
+		{SlipErrorWrongName, false, func(s *DepositSlip) bool { return s.Signature != "Nobody Atall" }},
+		{SlipErrorTotal, true, func(s *DepositSlip) bool { return s.Value != s.Cash+s.ChecksTotal() }},
+	}
+	for _, tt := range tests {
+		slip := &DepositSlip{Value: 5000, CashValue: 5000, AcctNum: 12345, WrittenAcct: "12345", ForDeposit: true, Signature: "Nobody Atall"}
+		if tt.itemized {
+			slip.Itemized, slip.Cash, slip.Checks = true, 5000, []int{2000, 1500}
+			slip.Value = slip.Cash + slip.ChecksTotal()
+		}
+		slip.applyError(tt.err)
+		assert.True(t, slip.IsWrong, tt.err)
+		assert.True(t, tt.mangled(slip), "%s: itemized %v", tt.err, tt.itemized)
+
+		till := NewTill()
+		till.DepositSlips = []*DepositSlip{slip}
+		report := till.Reconcile()
+		assert.EqualValues(t, map[string]int{tt.err.Describe(): 1}, report.BadSlips, tt.err)
+		assert.Zero(t, report.ValidSlips, tt.err)
+	}
+}
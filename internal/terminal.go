@@ -1,12 +1,16 @@
 package internal
 
 import (
+	"errors"
 	"fmt"
 	"github.com/hajimehoshi/ebiten/v2"
+	"github.com/hajimehoshi/ebiten/v2/inpututil"
 	"github.com/tinne26/etxt"
 	"golang.org/x/exp/maps"
 	"image/color"
 	"math"
+	"sort"
+	"strings"
 	"time"
 )
 
@@ -16,15 +20,32 @@ type Terminal struct {
 	txt   *etxt.Renderer
 
 	Operational bool
+	Focused     bool // Focused is true when the player is typing into the terminal.
 
 	bg *ebiten.Image
 
-	accountNumber []rune
-	keyDebounce   time.Time
+	input      []rune
+	blinkStart time.Time
+
+	history    []string // history of all commands entered, oldest first.
+	historyIdx int      // historyIdx is the index of the history entry being shown; len(history) when none is.
+
+	accountNumber string // accountNumber is the last account looked up.
 
 	lines []string
+
+	Inbox      []*Memo
+	reading    *Memo // reading is the memo open in the reader view, if any.
+	readOffset int
+	listing    bool // listing is true while the inbox is shown in the list view.
+	listOffset int
 }
 
+const maxTerminalLines = 6
+const maxTerminalCols = 20
+const terminalPrompt = ">"
+const maxInputLen = maxTerminalCols - len(terminalPrompt) - 1 // leaves room for the prompt and the cursor.
+
 func NewTerminal(txt *etxt.Renderer, scene *MainScene) *Terminal {
 	result := &Terminal{
 		scene: scene,
@@ -58,63 +79,103 @@ func (t *Terminal) DrawTo(screen *ebiten.Image) {
 	t.txt.SetFont(Resources.GetFont(DialogFont))
 	t.txt.SetColor(color.White)
 	t.txt.SetAlign(etxt.Top, etxt.Left)
-	t.txt.Draw("Account:", 5, 5)
 
-	t.txt.Draw(t.inputField(), 46, 5)
-
-	y := 5 + size + lineHeight
-	for _, line := range t.lines {
+	lines := t.lines
+	if t.reading != nil {
+		lines = t.readerLines()
+	} else if t.listing {
+		lines = t.listLines()
+	}
+	y := 5
+	for _, line := range lines {
 		t.txt.Draw(line, 5, y)
 		y += size + lineHeight
 	}
 
+	if unread := t.Unread(); unread > 0 && math.Sin(time.Now().Sub(t.blinkStart).Seconds()*math.Pi) > -0.5 {
+		t.txt.SetAlign(etxt.Top, etxt.Right)
+		t.txt.SetColor(mailIndicatorColor)
+		t.txt.Draw(T("terminal.mail", unread), t.Img.Bounds().Dx()-5, t.Img.Bounds().Dy()-5-size)
+		t.txt.SetColor(color.White)
+		t.txt.SetAlign(etxt.Top, etxt.Left)
+	}
+
+	y = t.Img.Bounds().Dy() - 5 - size
+	t.txt.Draw(terminalPrompt+t.inputField(), 5, y)
+
 	t.BaseSprite.DrawTo(screen)
 }
 
+var mailIndicatorColor = h2c("fdfe89")
+
 func (t *Terminal) inputField() string {
-	result := string(t.accountNumber)
-	if math.Sin(time.Now().Sub(t.keyDebounce).Seconds()*2*math.Pi) > 0 {
+	result := string(t.input)
+	if t.Focused && math.Sin(time.Now().Sub(t.blinkStart).Seconds()*2*math.Pi) > 0 {
 		return result + "_"
 	}
 	return result
 }
 
 func (t *Terminal) Update() {
+	if !t.Operational || !t.Focused {
+		return
+	}
 	t.handleKeys()
 }
 
+// Focus starts or stops capturing keyboard input for the terminal.
+func (t *Terminal) Focus(focused bool) {
+	if focused && !t.Focused {
+		t.blinkStart = time.Now()
+	}
+	t.Focused = focused && t.Operational
+}
+
 func (t *Terminal) handleKeys() {
-	if time.Now().Before(t.keyDebounce) {
-		return
-	}
-	for _, key := range heldKeys {
-		switch key {
-		case ebiten.KeyDigit0:
-			t.appendNumber('0')
-		case ebiten.KeyDigit1:
-			t.appendNumber('1')
-		case ebiten.KeyDigit2:
-			t.appendNumber('2')
-		case ebiten.KeyDigit3:
-			t.appendNumber('3')
-		case ebiten.KeyDigit4:
-			t.appendNumber('4')
-		case ebiten.KeyDigit5:
-			t.appendNumber('5')
-		case ebiten.KeyDigit6:
-			t.appendNumber('6')
-		case ebiten.KeyDigit7:
-			t.appendNumber('7')
-		case ebiten.KeyDigit8:
-			t.appendNumber('8')
-		case ebiten.KeyDigit9:
-			t.appendNumber('9')
-		case ebiten.KeyBackspace:
-			t.backspace()
+	if t.reading != nil {
+		t.handleReaderKeys()
+		return
+	}
+	if t.listing {
+		t.handleListKeys()
+		return
+	}
+	for _, r := range ebiten.AppendInputChars(nil) {
+		if len(t.input) >= maxInputLen {
+			break
 		}
+		t.input = append(t.input, []rune(strings.ToUpper(string(r)))...)
+	}
+	switch {
+	case repeatingKeyPressed(ebiten.KeyBackspace):
+		t.backspace()
+	case inpututil.IsKeyJustPressed(ebiten.KeyEnter) || inpututil.IsKeyJustPressed(ebiten.KeyNumpadEnter):
+		t.enter()
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowUp):
+		t.recall(-1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyArrowDown):
+		t.recall(1)
+	case inpututil.IsKeyJustPressed(ebiten.KeyEscape):
+		t.Focus(false)
 	}
 }
 
+// repeatingKeyPressed returns true when the key was just pressed, and periodically while it's held down.
+func repeatingKeyPressed(key ebiten.Key) bool {
+	const (
+		delay    = 30
+		interval = 3
+	)
+	d := inpututil.KeyPressDuration(key)
+	if d == 1 {
+		return true
+	}
+	if d >= delay && (d-delay)%interval == 0 {
+		return true
+	}
+	return false
+}
+
 func union[T comparable](A, B []T) []T {
 	keys := make(map[T]struct{})
 	for _, a := range A {
@@ -126,50 +187,250 @@ func union[T comparable](A, B []T) []T {
 	return maps.Keys(keys)
 }
 
-func (t *Terminal) ValidateCheck(check *Check) {
-	if check.Valid {
-		t.lines = []string{"CHECK IS VALID"}
-	} else {
-		t.lines = []string{"INVALID INVALID INVALID"}
+func (t *Terminal) backspace() {
+	if len(t.input) == 0 {
+		return
 	}
+	t.input = t.input[:len(t.input)-1]
 }
 
-func (t *Terminal) backspace() {
-	if len(t.accountNumber) == 0 {
+// recall replaces the input with an entry from the command history.
+func (t *Terminal) recall(delta int) {
+	if len(t.history) == 0 {
 		return
 	}
-	t.lines = nil
-	t.accountNumber = t.accountNumber[:len(t.accountNumber)-1]
-	t.keyDebounce = time.Now().Add(150 * time.Millisecond)
+	t.historyIdx = clamp(t.historyIdx+delta, 0, len(t.history))
+	if t.historyIdx == len(t.history) {
+		t.input = nil
+		return
+	}
+	t.input = []rune(t.history[t.historyIdx])
+}
+
+func (t *Terminal) enter() {
+	cmd := strings.TrimSpace(string(t.input))
+	t.input = nil
+	if cmd == "" {
+		return
+	}
+	t.history = append(t.history, cmd)
+	t.historyIdx = len(t.history)
+	t.Exec(cmd)
+}
+
+// Print appends lines to the terminal output, wrapping any too wide for the screen and scrolling old lines away.
+func (t *Terminal) Print(lines ...string) {
+	for _, line := range lines {
+		if len(line) > maxTerminalCols {
+			t.lines = append(t.lines, wrapText(line, maxTerminalCols)...)
+		} else {
+			t.lines = append(t.lines, line)
+		}
+	}
+	if len(t.lines) > maxTerminalLines {
+		t.lines = t.lines[len(t.lines)-maxTerminalLines:]
+	}
 }
 
-func (t *Terminal) appendNumber(n rune) {
-	if len(t.accountNumber) == 5 {
+// Exec runs the provided terminal command, printing its output.
+func (t *Terminal) Exec(cmd string) {
+	tokens := strings.Fields(strings.ToUpper(cmd))
+	if len(tokens) == 0 {
+		return
+	}
+	t.lines = nil
+	if len(tokens) == 1 && isAcctNum(tokens[0]) { // bare account numbers are looked up
+		t.lookup(tokens)
 		return
 	}
-	t.accountNumber = append(t.accountNumber, n)
-	t.keyDebounce = time.Now().Add(150 * time.Millisecond)
-	t.lookup()
+	for _, c := range terminalCommands {
+		if c.Name == tokens[0] {
+			c.Run(t, tokens[1:])
+			return
+		}
+	}
+	t.Print(T("terminal.unknown_command"), T("terminal.type_help"))
 }
 
-func (t *Terminal) lookup() {
-	if len(t.accountNumber) < 5 {
+type terminalCommand struct {
+	Name  string
+	Usage string
+	Run   func(t *Terminal, args []string)
+}
+
+var terminalCommands []terminalCommand
+
+func init() {
+	terminalCommands = []terminalCommand{
+		{Name: "HELP", Usage: "HELP [CMD]", Run: (*Terminal).help},
+		{Name: "LOOKUP", Usage: "LOOKUP <ACCT>", Run: (*Terminal).lookup},
+		{Name: "FIND", Usage: "FIND <NAME>", Run: (*Terminal).find},
+		{Name: "HISTORY", Usage: "HISTORY <ACCT>", Run: (*Terminal).accountHistory},
+		{Name: "POST", Usage: "POST DEP|WD|CHK [ACCT] <AMT>", Run: (*Terminal).post},
+		{Name: "HOLD", Usage: "HOLD [ACCT]", Run: (*Terminal).hold},
+		{Name: "MAIL", Usage: "MAIL", Run: (*Terminal).mail},
+		{Name: "READ", Usage: "READ [N]", Run: (*Terminal).read},
+		{Name: "CLEAR", Usage: "CLEAR", Run: func(t *Terminal, _ []string) { t.lines = nil }},
+	}
+}
+
+// help lists the commands, or shows how to use the command named in args; the full usage of every command wouldn't
+// fit on the screen.
+func (t *Terminal) help(args []string) {
+	if len(args) == 0 {
+		names := make([]string, len(terminalCommands))
+		for i, c := range terminalCommands {
+			names[i] = c.Name
+		}
+		t.Print(strings.Join(names, " "))
+		t.Print(T("terminal.help_usage"))
 		return
 	}
-	acct, ok := t.scene.Day.Accounts[t.GetAccountNumber()]
+	for _, c := range terminalCommands {
+		if c.Name == args[0] {
+			t.Print(T("terminal.usage", c.Usage))
+			return
+		}
+	}
+	t.Print(T("terminal.unknown_command"), T("terminal.type_help"))
+}
+
+// account looks up the account named in args, or the last account looked up if args is empty.
+func (t *Terminal) account(args []string) *Account {
+	num := t.accountNumber
+	if len(args) > 0 {
+		num = args[0]
+	}
+	if num == "" {
+		t.Print(T("terminal.no_account"))
+		return nil
+	}
+	acct, ok := t.scene.Day.Accounts[num]
 	if acct == nil || !ok {
-		t.lines = []string{"--ACCOUNT NOT FOUND--"}
+		t.Print(T("terminal.account_not_found"))
+		return nil
+	}
+	t.accountNumber = num
+	return acct
+}
+
+func (t *Terminal) lookup(args []string) {
+	acct := t.account(args)
+	if acct == nil {
 		return
 	}
-	t.lines = []string{
-		fmt.Sprintf("Owner: %s", acct.Owner),
-		fmt.Sprintf("Checking Balance: %.02f", float32(acct.Checking)/100.0),
+	t.Print(
+		T("terminal.acct", acct.Number),
+		T("terminal.owner", acct.Owner),
+		T("terminal.checking", fmtCents(acct.Checking)),
+	)
+	if acct.Hold {
+		t.Print(T("terminal.on_hold"))
 	}
 }
 
-func (t *Terminal) GetAccountNumber() string {
-	if len(t.accountNumber) != 5 {
-		return ""
+func (t *Terminal) find(args []string) {
+	if len(args) == 0 {
+		t.Print(T("terminal.usage", "FIND <NAME>"))
+		return
+	}
+	name := strings.Join(args, " ")
+	var found []string
+	for num, acct := range t.scene.Day.Accounts {
+		if strings.Contains(strings.ToUpper(acct.Owner), name) {
+			found = append(found, fmt.Sprintf("%s %s", num, acct.Owner))
+		}
+	}
+	if len(found) == 0 {
+		t.Print(T("terminal.no_matches"))
+		return
+	}
+	sort.Strings(found)
+	t.Print(found...)
+}
+
+func (t *Terminal) accountHistory(args []string) {
+	acct := t.account(args)
+	if acct == nil {
+		return
+	}
+	if len(acct.History) == 0 {
+		t.Print(T("terminal.no_history", acct.Number))
+		return
+	}
+	for _, p := range acct.History {
+		t.Print(fmt.Sprintf("%-3s %10s", p.Kind, fmtCents(p.Amount)))
+	}
+}
+
+// post posts a transaction to the account named in args, or to the last account looked up, which leaves room on the
+// input line for cents.
+func (t *Terminal) post(args []string) {
+	if len(args) < 2 || len(args) > 3 {
+		t.Print(T("terminal.usage", "POST DEP|WD|CHK [ACCT] <AMT>"))
+		return
 	}
-	return string(t.accountNumber)
+	kind := PostingKind(args[0])
+	if !kind.Valid() {
+		t.Print(T("terminal.unknown_kind", args[0]), T("terminal.use_kinds"))
+		return
+	}
+	amtArg := args[len(args)-1]
+	amt, err := Lang.ParseMoney(amtArg)
+	if err != nil || amt <= 0 {
+		t.Print(T("terminal.bad_amount", amtArg))
+		return
+	}
+	acct := t.account(args[1 : len(args)-1])
+	if acct == nil {
+		return
+	}
+	if err := t.scene.Day.Post(acct, kind, amt); err != nil {
+		t.Print(postingError(err))
+		return
+	}
+	t.Print(
+		T("terminal.posted", kind, fmtCents(amt)),
+		T("terminal.balance", fmtCents(acct.Checking)),
+	)
+}
+
+// postingError describes an error from posting to the ledger.
+func postingError(err error) string {
+	switch {
+	case errors.Is(err, ErrAccountOnHold):
+		return T("terminal.err_on_hold")
+	case errors.Is(err, ErrInsufficientFunds):
+		return T("terminal.err_insufficient_funds")
+	}
+	return strings.ToUpper(err.Error())
+}
+
+func (t *Terminal) hold(args []string) {
+	acct := t.account(args)
+	if acct == nil {
+		return
+	}
+	acct.Hold = !acct.Hold
+	if acct.Hold {
+		t.Print(T("terminal.hold_placed", acct.Number))
+	} else {
+		t.Print(T("terminal.hold_released", acct.Number))
+	}
+}
+
+func isAcctNum(s string) bool {
+	if len(s) != 5 {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func (t *Terminal) GetAccountNumber() string {
+	return t.accountNumber
 }
@@ -0,0 +1,52 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestTerminal_Help(t *testing.T) {
+	term := &Terminal{}
+	term.Exec("help")
+	assert.LessOrEqual(t, len(term.lines), maxTerminalLines-1, "the command list fits with room to spare")
+	for _, line := range term.lines {
+		assert.LessOrEqual(t, len(line), maxTerminalCols, line)
+	}
+
+	for _, c := range terminalCommands {
+		term.Exec("help " + c.Name)
+		assert.EqualValues(t, T("terminal.usage", c.Usage), strings.Join(term.lines, " "))
+		for _, line := range term.lines {
+			assert.LessOrEqual(t, len(line), maxTerminalCols, line)
+		}
+	}
+}
+
+func TestTerminal_Mail(t *testing.T) {
+	term := &Terminal{}
+	for i := 0; i < 8; i++ {
+		term.Inbox = append(term.Inbox, &Memo{Text: "HELLO", Read: i < 6})
+	}
+	term.Exec("mail")
+	assert.True(t, term.listing)
+	lines := term.listLines()
+	assert.Len(t, lines, maxTerminalLines)
+	assert.EqualValues(t, "*8 HELLO", lines[len(lines)-1], "scrolled to the unread memos")
+}
+
+func TestTerminal_Post(t *testing.T) {
+	day := &Day{Accounts: map[string]*Account{"12345": {Number: "12345", Checking: 1000, StartBalance: 1000}}}
+	term := &Terminal{scene: &MainScene{Day: day}}
+	term.Exec("post dep 12345 1")
+	assert.EqualValues(t, 1100, day.Accounts["12345"].Checking)
+
+	cmd := "POST CHK 99.99" // the last account looked up.
+	assert.LessOrEqual(t, len(cmd), maxInputLen)
+	term.Exec(cmd)
+	assert.EqualValues(t, 11099, day.Accounts["12345"].Checking)
+	assert.Len(t, day.Ledger.Posted, 2)
+
+	term.Exec("post wd")
+	assert.EqualValues(t, T("terminal.usage", "POST DEP|WD|CHK [ACCT] <AMT>"), strings.Join(term.lines, " "))
+}
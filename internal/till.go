@@ -6,7 +6,6 @@ import (
 	"github.com/Frabjous-Studios/bankwave/internal/debug"
 	"image"
 	"math/rand"
-	"text/template"
 )
 
 const CoinTargets = 0
@@ -23,6 +22,7 @@ type Till struct {
 
 	DepositSlips []*DepositSlip
 	Checks       []*Check
+	Robberies    []*Robbery // Robberies are today's hold-ups; cash a robber got away with isn't expected back.
 }
 
 func NewTill() *Till {
@@ -54,34 +54,49 @@ func NewTill() *Till {
 type ReconciliationReport struct {
 	ValidSlips int
 	WTFSlips   int
+	BadSlips   map[string]int // BadSlips counts the accepted slips with mistakes on them, by SlipError.
 
 	BillCount     map[string]int
 	CoinCount     map[string]int
 	ExpectedValue string
 	ActualValue   string
 	Imbalance     string
+
+	ExpectedChecks string // ExpectedChecks is the total of all checks listed on itemized slips.
+	ActualChecks   string // ActualChecks is the total of all checks in the till.
+
+	Ledger    LedgerReport
+	Disposals DisposalAudit
+
+	Robbed      int // Robbed is the amount of cash lost to robberies today, in cents.
+	FalseAlarms int
+	LongestLine int
+	StormOffs   int
 }
 
 func (t *Till) Reconcile() *ReconciliationReport {
 	report := ReconciliationReport{
 		BillCount: make(map[string]int),
 		CoinCount: make(map[string]int),
+		BadSlips:  make(map[string]int),
 	}
 
-	expectedValue := t.StartValue
+	expectedValue, expectedChecks := t.Expected()
 	for _, slip := range t.DepositSlips {
-		if slip.ForDeposit {
-			expectedValue += slip.Value
-			report.ValidSlips++
-		} else if slip.ForWithdrawal {
-			expectedValue -= slip.Value
-			report.ValidSlips++
-		} else {
+		if !slip.ForDeposit && !slip.ForWithdrawal {
 			report.WTFSlips++ // wtf? what is this?!
+			continue
+		}
+		if slip.Error != SlipErrorNone {
+			report.BadSlips[slip.Error.Describe()]++
+		} else {
+			report.ValidSlips++
 		}
 	}
 	// TODO: handle checks separately
+	actualChecks := 0
 	for _, check := range t.Checks {
+		actualChecks += check.Value
 		if check.Valid && check.Endorsed && check.Signed {
 			report.ValidSlips++
 		} else {
@@ -98,44 +113,23 @@ func (t *Till) Reconcile() *ReconciliationReport {
 			report.BillCount[fmt.Sprintf("b%d", money.Value/100)]++
 		}
 	}
-	report.ExpectedValue = fmt.Sprintf("%.02f", float32(expectedValue)/100)
-	report.ActualValue = fmt.Sprintf("%.02f", float32(t.Value())/100)
-	report.Imbalance = fmt.Sprintf("%.02f", float32(t.Value()-expectedValue)/100)
+	report.ExpectedValue = fmtCents(expectedValue)
+	report.ActualValue = fmtCents(t.Value())
+	report.Imbalance = fmtCents(t.Imbalance())
+	report.ExpectedChecks = fmtCents(expectedChecks)
+	report.ActualChecks = fmtCents(actualChecks)
 
 	return &report
 }
 
-var reportTemplate *template.Template
-
-func init() {
-	var err error
-	const T = `     CURRENCY
---Scrip--     --Tokens--
-  1: {{.BillCount.b1 | printf "%3d"}}       1: {{.CoinCount.c1 | printf "%3d"}}
-  5: {{.BillCount.b5 | printf "%3d"}}       5: {{.CoinCount.c5 | printf "%3d"}}
- 10: {{.BillCount.b10 | printf "%3d"}}      10: {{.CoinCount.c10 | printf "%3d"}}               
- 20: {{.BillCount.b20 | printf "%3d"}}       25: {{.CoinCount.c25 | printf "%3d"}}
-100: {{.BillCount.b100 | printf "%3d"}}      50: {{.CoinCount.c50 | printf "%3d"}}
-
---Deposit Slips--
-  Valid:  {{.ValidSlips}}
-Invalid:  {{.WTFSlips}}
-
--- RECONCILIATION --
-  EXPECTED = {{.ExpectedValue}}
-      TILL = {{.ExpectedValue}}
- IMBALANCE = {{.Imbalance}}
-`
-	reportTemplate, err = template.New("").Parse(T)
+func (t *ReconciliationReport) String() string {
+	tmpl, err := Lang.Report()
 	if err != nil {
-		panic(fmt.Errorf("unable to parse reconciliation template: %v", err))
+		debug.Printf("error loading report template: %v", err)
+		return ""
 	}
-
-}
-
-func (t *ReconciliationReport) String() string {
 	var w bytes.Buffer
-	err := reportTemplate.Execute(&w, t)
+	err = tmpl.Execute(&w, t)
 	if err != nil {
 		debug.Printf("error executing template: %v", err)
 	}
@@ -185,6 +179,60 @@ func (t *Till) dropStack(s *Stack) bool {
 	return true
 }
 
+// Return puts cash back in its slot in the till, e.g. once it's recovered from a robber. Returns the money put back,
+// which needs to be added to the scene; stacks come back as loose bills.
+func (t *Till) Return(s Sprite) []*Money {
+	var result []*Money
+	switch s := s.(type) {
+	case *Money:
+		if s.IsCoin {
+			t.returnMoney(s, CoinTargets, idxForCoin(s.Value))
+		} else {
+			t.returnMoney(s, BillTargets, idxForDenom(s.Value/100))
+		}
+		result = append(result, s)
+	case *Stack:
+		for i := 0; i < s.Count; i++ {
+			bill := newBill(s.Value, image.Point{})
+			t.returnMoney(bill, BillTargets, idxForDenom(s.Value))
+			result = append(result, bill)
+		}
+	}
+	return result
+}
+
+func (t *Till) returnMoney(m *Money, targets, idx int) {
+	if idx < 0 {
+		debug.Println("no slot in the till for", m.Value)
+		return
+	}
+	pos := t.DropTargets[targets][idx].Min.Add(t.Pos())
+	m.X, m.Y = pos.X, pos.Y
+	if m.IsCoin {
+		t.CoinSlots[idx] = append(t.CoinSlots[idx], m)
+	} else {
+		t.BillSlots[idx] = append(t.BillSlots[idx], m)
+	}
+}
+
+// idxForCoin finds the slot for coins worth the provided number of cents.
+func idxForCoin(cents int) int {
+	switch cents {
+	case 1:
+		return 0
+	case 5:
+		return 1
+	case 10:
+		return 2
+	case 25:
+		return 3
+	case 50:
+		return 4
+	default:
+		return -1
+	}
+}
+
 func idxForDenom(denom int) int {
 	switch denom {
 	case 1:
@@ -239,6 +287,29 @@ func (t *Till) dropMoney(m *Money) bool {
 	return true
 }
 
+// Expected returns how much cash and how much in checks should be in the till, according to the slips in it.
+func (t *Till) Expected() (cash, checks int) {
+	cash = t.StartValue
+	for _, slip := range t.DepositSlips {
+		if slip.IsWithdrawal {
+			cash -= slip.CashTotal()
+		} else if slip.ForDeposit {
+			cash += slip.CashTotal()
+			checks += slip.ChecksTotal()
+		}
+	}
+	for _, r := range t.Robberies {
+		cash -= r.Taken()
+	}
+	return cash, checks
+}
+
+// Imbalance is the difference between the cash in the till and the cash which should be in it, in cents.
+func (t *Till) Imbalance() int {
+	expected, _ := t.Expected()
+	return t.Value() - expected
+}
+
 func (t *Till) Value() int {
 	var result int
 	for _, stack := range t.BillSlots {
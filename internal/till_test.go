@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+// robbedTill is a till holding two $20 bills, one of which was handed to a robber.
+func robbedTill(outcome RobberyOutcome) (*Till, *Money) {
+	t := NewTill()
+	kept, handed := &Money{BaseSprite: &BaseSprite{}, Value: 2000}, &Money{BaseSprite: &BaseSprite{}, Value: 2000}
+	t.BillSlots[idxForDenom(20)] = []*Money{kept}
+	t.StartValue = 4000
+	t.Robberies = []*Robbery{{Demand: 2000, Handed: 2000, Outcome: outcome, Loot: []Sprite{handed}}}
+	return t, handed
+}
+
+func TestTill_Expected_RobberGotAway(t *testing.T) {
+	for _, outcome := range []RobberyOutcome{RobberyGotAway, RobberyEscalated} {
+		till, _ := robbedTill(outcome)
+		cash, _ := till.Expected()
+		assert.EqualValues(t, 2000, cash, outcome)
+		assert.Zero(t, till.Imbalance(), outcome)
+	}
+}
+
+func TestTill_Expected_LootRecovered(t *testing.T) {
+	for _, outcome := range []RobberyOutcome{RobberyCaught, RobberyFled} {
+		till, handed := robbedTill(outcome)
+		assert.EqualValues(t, -2000, till.Imbalance(), "%s: until the loot is back", outcome)
+
+		assert.EqualValues(t, []*Money{handed}, till.Return(handed), outcome)
+		cash, _ := till.Expected()
+		assert.EqualValues(t, 4000, cash, outcome)
+		assert.EqualValues(t, 4000, till.Value(), outcome)
+		assert.Zero(t, till.Imbalance(), outcome)
+	}
+}
+
+func TestTill_Expected_StackRecovered(t *testing.T) {
+	till := NewTill()
+	till.StartValue = 4000
+	stack := &Stack{BaseSprite: &BaseSprite{}, Value: 20, Count: 2}
+	till.Robberies = []*Robbery{{Demand: 4000, Handed: 4000, Outcome: RobberyCaught, Loot: []Sprite{stack}}}
+	assert.EqualValues(t, -4000, till.Imbalance())
+
+	returned := till.Return(stack)
+	assert.Len(t, returned, 2)
+	assert.EqualValues(t, 4000, till.Value())
+	assert.Zero(t, till.Imbalance())
+}
+
+func TestTill_Expected_BothBoxes(t *testing.T) {
+	till := NewTill()
+	till.StartValue = 10000
+	slip := &DepositSlip{Value: 2500, CashValue: 2500, ForWithdrawal: true, IsWithdrawal: true}
+	slip.applyError(SlipErrorBothBoxes)
+	till.DepositSlips = []*DepositSlip{slip}
+
+	cash, _ := till.Expected()
+	assert.EqualValues(t, 7500, cash)
+}
@@ -1,5 +1,45 @@
 package internal
 
+import (
+	"math"
+	"strconv"
+)
+
+// fmtCents formats an amount in cents as dollars and cents, the way the current locale writes money.
+func fmtCents(cents int) string {
+	return Lang.Money(cents)
+}
+
+// fmtDollars formats an amount in cents as whole dollars, dropping any cents.
+func fmtDollars(cents int) string {
+	return Lang.Money(cents - cents%100)
+}
+
+// parseCents parses an amount of dollars (e.g. "12" or "12.50") into cents.
+func parseCents(s string) (int, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(f * 100)), nil
+}
+
+// min is the smaller of x and y; go.mod predates the builtin.
+func min(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+// max is the larger of x and y.
+func max(x, y int) int {
+	if x < y {
+		return y
+	}
+	return x
+}
+
 func randMapValue[K comparable, V any](m map[K]V) V {
 	var zero V
 	for _, v := range m { // uses fact that map range loops are random
@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"github.com/hajimehoshi/ebiten/v2"
+	"image"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// WalkStyle is how a customer gets to and from the window.
+type WalkStyle uint8
+
+const (
+	WalkNormal WalkStyle = iota // WalkNormal strolls in from the left and out to the right, bobbing along.
+	WalkHover                   // WalkHover drops in from above and flies back out the same way; for the drone.
+	WalkStorm                   // WalkStorm stomps in and out fast, shaking with rage.
+)
+
+const (
+	ApproachTime       = 900 * time.Millisecond
+	DepartTime         = 700 * time.Millisecond
+	WalkInDistance     = 60 // WalkInDistance is how far customers walk to get to the window, in pixels.
+	HoverDistance      = 100
+	WalkBobHeight      = 3
+	WalkStepsPerSecond = 3
+)
+
+// walkStyle picks how the customer for the provided node walks.
+func walkStyle(nodeID string) WalkStyle {
+	switch {
+	case strings.HasPrefix(nodeID, "drone"):
+		return WalkHover
+	case strings.HasPrefix(nodeID, "Karen"):
+		return WalkStorm
+	}
+	return WalkNormal
+}
+
+// Walk tweens a customer to or from the window.
+type Walk struct {
+	Style   WalkStyle
+	Leaving bool
+
+	start    time.Time
+	from, to image.Point
+	duration time.Duration
+}
+
+// newWalk starts the provided customer walking to the window, or away from it if leaving is set.
+func newWalk(c *Customer, leaving bool) *Walk {
+	w := &Walk{
+		Style:    c.Walk,
+		Leaving:  leaving,
+		start:    time.Now(),
+		duration: ApproachTime,
+	}
+	window := image.Pt(portraitStartX, portraitStartY)
+	var away image.Point
+	switch c.Walk {
+	case WalkHover:
+		away = window.Sub(image.Pt(0, HoverDistance))
+	case WalkStorm:
+		away = window.Add(image.Pt(WalkInDistance, 0))
+		w.duration /= 2
+	default:
+		away = window.Sub(image.Pt(WalkInDistance, 0))
+	}
+	if leaving {
+		w.from, w.to = c.Pos(), away
+		if c.Walk != WalkHover {
+			w.to = image.Pt(320, c.Y) // off the right edge of the screen.
+		}
+		w.duration = w.duration * DepartTime / ApproachTime
+	} else {
+		w.from, w.to = away, window
+	}
+	c.SetPos(w.from)
+	return w
+}
+
+// Step moves the customer along their walk. Returns true once they've arrived.
+func (w *Walk) Step(c *Customer) bool {
+	elapsed := time.Now().Sub(w.start)
+	t := math.Min(1, elapsed.Seconds()/w.duration.Seconds())
+
+	var eased float64
+	if w.Leaving {
+		eased = t * t // speed up on the way out
+		c.Fade = t
+	} else {
+		eased = 1 - math.Pow(1-t, 3) // slow down on the way in
+		c.Fade = 1 - t
+	}
+	x := float64(w.from.X) + eased*float64(w.to.X-w.from.X)
+	y := float64(w.from.Y) + eased*float64(w.to.Y-w.from.Y)
+
+	switch w.Style {
+	case WalkNormal:
+		y -= math.Abs(math.Sin(elapsed.Seconds()*WalkStepsPerSecond*math.Pi)) * WalkBobHeight
+	case WalkStorm:
+		y -= math.Abs(math.Sin(elapsed.Seconds()*2*WalkStepsPerSecond*math.Pi)) * 2 * WalkBobHeight
+		x += float64(rand.Intn(3) - 1)
+	}
+	if t >= 1 { // land exactly where we're headed.
+		x, y = float64(w.to.X), float64(w.to.Y)
+	}
+	c.SetPos(image.Pt(int(math.Round(x)), int(math.Round(y))))
+	return t >= 1
+}
+
+// DrawTo draws the customer, faded out as needed.
+func (c *Customer) DrawTo(screen *ebiten.Image) {
+	opt := &ebiten.DrawImageOptions{}
+	dx, dy := c.moodOffset()
+	opt.GeoM.Translate(float64(c.X)+dx, float64(c.Y)+dy)
+	opt.GeoM.Scale(ScaleFactor, ScaleFactor)
+	opt.ColorScale.ScaleAlpha(float32(1 - c.Fade))
+	screen.DrawImage(c.Img, opt)
+}
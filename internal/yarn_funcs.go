@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"github.com/DrJosh9000/yarn"
+	"math/rand"
+)
+
+// yarnFuncs are the functions Yarn scripts can call to ask about the state of the game. Money is in dollars.
+func (m *MainScene) yarnFuncs() yarn.FuncMap {
+	return yarn.FuncMap{
+		"account_balance":          m.accountBalance,
+		"till_value":               func() float32 { return float32(m.till.Value()) / 100 },
+		"customer_cash_on_counter": m.customerCashOnCounter,
+		"times_met":                m.timesMet,
+		"day":                      func() float32 { return float32(m.dayIdx + 1) },
+		"chance":                   func(p float32) bool { return rand.Float32() < p },
+	}
+}
+
+// accountBalance is the checking balance of the provided account; 0 if there's no such account today.
+func (m *MainScene) accountBalance(acctNum string) float32 {
+	acct, ok := m.Day.Accounts[acctNum]
+	if !ok {
+		return 0
+	}
+	return float32(acct.Checking) / 100
+}
+
+func (m *MainScene) customerCashOnCounter() float32 {
+	if m.Customer == nil {
+		return 0
+	}
+	return float32(m.Customer.CashOnCounter) / 100
+}
+
+// timesMet is how many times the regular with the provided name has visited, counting this visit; 0 for anybody else.
+func (m *MainScene) timesMet(name string) float32 {
+	for _, reg := range m.regulars {
+		if reg.Name != name {
+			continue
+		}
+		if m.Customer != nil && m.Customer.Regular == reg {
+			return float32(len(reg.Visits) + 1) // they're at the window, so this visit counts too.
+		}
+		return float32(len(reg.Visits))
+	}
+	return 0
+}
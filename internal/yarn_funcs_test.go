@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"github.com/Frabjous-Studios/bankwave/internal/yarnc"
+	"github.com/stretchr/testify/assert"
+	"reflect"
+	"testing"
+)
+
+// TestYarnFuncs checks that the compiler's idea of the game's functions matches the functions the game registers.
+func TestYarnFuncs(t *testing.T) {
+	funcs := (&MainScene{}).yarnFuncs()
+	for name := range yarnc.GameFunctions {
+		assert.Contains(t, funcs, name, "yarnc knows about %s, but the game doesn't register it", name)
+	}
+	for name, f := range funcs {
+		sig, ok := yarnc.GameFunctions[name]
+		if !assert.True(t, ok, "the game registers %s, but yarnc doesn't know about it", name) {
+			continue
+		}
+		typ := reflect.TypeOf(f)
+		if !assert.Equal(t, len(sig.Params), typ.NumIn(), "%s takes the wrong number of arguments", name) {
+			continue
+		}
+		for i, param := range sig.Params {
+			if param != yarnc.TypeAny {
+				assert.Equal(t, param, yarnType(typ.In(i)), "argument %d of %s", i, name)
+			}
+		}
+		if assert.Equal(t, 1, typ.NumOut(), "%s should return one value", name) {
+			assert.Equal(t, sig.Returns, yarnType(typ.Out(0)), "return value of %s", name)
+		}
+	}
+}
+
+// yarnType is the Yarn type Go values of the provided type become.
+func yarnType(typ reflect.Type) yarnc.Type {
+	switch typ.Kind() {
+	case reflect.Float32, reflect.Float64, reflect.Int, reflect.Uint:
+		return yarnc.TypeNumber
+	case reflect.String:
+		return yarnc.TypeString
+	case reflect.Bool:
+		return yarnc.TypeBool
+	}
+	return yarnc.TypeAny
+}
@@ -0,0 +1,135 @@
+package yarnc
+
+import "fmt"
+
+// Pos is a position in a Yarn source file.
+type Pos struct {
+	File string
+	Line int // Line is 1-indexed.
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
+
+// File is a parsed Yarn source file.
+type File struct {
+	Name  string // Name is the path of the file, relative to the directory of Yarn sources.
+	Nodes []*Node
+}
+
+// Header is a `key: value` line from the top of a node.
+type Header struct {
+	Key, Value string
+}
+
+// Node is a single Yarn node; everything between a title and `===`.
+type Node struct {
+	Pos
+	Title   string
+	Headers []Header // Headers are every header of the node, including its title, in order.
+	Tags    []string // Tags are listed in the node's `tags` header.
+	Body    []Stmt
+}
+
+// Header returns the value of the provided header, or "" if the node doesn't have it.
+func (n *Node) Header(key string) string {
+	for _, h := range n.Headers {
+		if h.Key == key {
+			return h.Value
+		}
+	}
+	return ""
+}
+
+// Stmt is a statement in the body of a node.
+type Stmt interface {
+	Position() Pos
+}
+
+// Line is a line of dialogue. Expressions in braces are replaced by `{0}`, `{1}`, etc. in Text.
+type Line struct {
+	Pos
+	ID    string
+	Text  string
+	Exprs []Expr
+	Tags  []string
+	Cond  Expr // Cond is the condition on an option; always nil for lines which aren't options.
+}
+
+// Option is a single option in an option group, with the statements run when it's picked.
+type Option struct {
+	*Line
+	Body []Stmt
+}
+
+// OptionGroup is a group of options presented to the player at once.
+type OptionGroup struct {
+	Pos
+	Options []*Option
+}
+
+// Clause is one branch of an if statement.
+type Clause struct {
+	Pos
+	Cond Expr // Cond is nil for the else clause.
+	Body []Stmt
+}
+
+// If is an if statement, with its elseif and else clauses.
+type If struct {
+	Pos
+	Clauses []*Clause
+}
+
+// Set assigns a value to a variable.
+type Set struct {
+	Pos
+	Var   string
+	Value Expr
+}
+
+// Declare declares a variable, and its initial value.
+type Declare struct {
+	Pos
+	Var   string
+	Value Expr
+}
+
+// Jump runs another node. Target is empty when the node is picked by Expr.
+type Jump struct {
+	Pos
+	Target string
+	Expr   Expr
+}
+
+// Stop stops the dialogue.
+type Stop struct {
+	Pos
+}
+
+// Command is a command for the game to run. Expressions in braces are replaced by `{0}`, `{1}`, etc. in Text.
+type Command struct {
+	Pos
+	Text  string
+	Exprs []Expr
+}
+
+func (p Pos) Position() Pos { return p }
+
+// Walk calls visit for every statement in the provided statements, including those nested in options and ifs.
+func Walk(stmts []Stmt, visit func(Stmt)) {
+	for _, s := range stmts {
+		visit(s)
+		switch s := s.(type) {
+		case *OptionGroup:
+			for _, o := range s.Options {
+				Walk(o.Body, visit)
+			}
+		case *If:
+			for _, c := range s.Clauses {
+				Walk(c.Body, visit)
+			}
+		}
+	}
+}
@@ -0,0 +1,534 @@
+package yarnc
+
+import (
+	"fmt"
+	"github.com/DrJosh9000/yarn/bytecode"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Func is the signature of a function Yarn scripts can call.
+type Func struct {
+	Params  []Type
+	Returns Type
+}
+
+// Builtins are the functions Yarn provides to every script.
+var Builtins = map[string]Func{
+	"dice":          {[]Type{TypeNumber}, TypeNumber},
+	"random":        {nil, TypeNumber},
+	"random_range":  {[]Type{TypeNumber, TypeNumber}, TypeNumber},
+	"round":         {[]Type{TypeNumber}, TypeNumber},
+	"round_places":  {[]Type{TypeNumber, TypeNumber}, TypeNumber},
+	"floor":         {[]Type{TypeNumber}, TypeNumber},
+	"ceil":          {[]Type{TypeNumber}, TypeNumber},
+	"inc":           {[]Type{TypeNumber}, TypeNumber},
+	"dec":           {[]Type{TypeNumber}, TypeNumber},
+	"decimal":       {[]Type{TypeNumber}, TypeNumber},
+	"visited":       {[]Type{TypeString}, TypeBool},
+	"visited_count": {[]Type{TypeString}, TypeNumber},
+}
+
+// GameFunctions are the functions the game registers in internal/yarn_funcs.go; TestYarnFuncs keeps the two in step.
+var GameFunctions = map[string]Func{
+	"account_balance":          {[]Type{TypeAny}, TypeNumber},
+	"till_value":               {nil, TypeNumber},
+	"customer_cash_on_counter": {nil, TypeNumber},
+	"times_met":                {[]Type{TypeString}, TypeNumber},
+	"day":                      {nil, TypeNumber},
+	"chance":                   {[]Type{TypeNumber}, TypeBool},
+}
+
+// Functions are every function Yarn scripts can call.
+var Functions = make(map[string]Func)
+
+func init() {
+	for name, f := range Builtins {
+		Functions[name] = f
+	}
+	for name, f := range GameFunctions {
+		Functions[name] = f
+	}
+}
+
+// TagLastLine is added to every line which comes right before a group of options.
+const TagLastLine = "lastline"
+
+// Output is a compiled Yarn program, along with the lines of dialogue it refers to.
+type Output struct {
+	Program *bytecode.Program
+	Lines   []*TableLine
+}
+
+// TableLine is a line of dialogue in the string table.
+type TableLine struct {
+	*Line
+	Node string
+}
+
+// CompileDir parses and compiles every .yarn file in the provided directory into a program with the provided name.
+func CompileDir(dir, name string) (*Output, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yarn"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+	var files []*File
+	var errs ErrorList
+	for _, path := range paths {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := Parse(filepath.Base(path), string(src))
+		if list, ok := err.(ErrorList); ok {
+			errs = append(errs, list...)
+		}
+		files = append(files, f)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return Compile(name, files)
+}
+
+// Compile compiles the provided files into a single program with the provided name.
+func Compile(name string, files []*File) (*Output, error) {
+	c := &compiler{
+		vars:  make(map[string]Type),
+		nodes: make(map[string]*Node),
+		out: &Output{Program: &bytecode.Program{
+			Name:          name,
+			Nodes:         make(map[string]*bytecode.Node),
+			InitialValues: make(map[string]*bytecode.Operand),
+		}},
+	}
+	for _, f := range files {
+		for _, n := range f.Nodes {
+			if prev, ok := c.nodes[n.Title]; ok {
+				c.errorf(n.Pos, "node %s is already defined at %v", n.Title, prev.Pos)
+				continue
+			}
+			c.nodes[n.Title] = n
+		}
+	}
+	c.infer(files)
+	for _, f := range files {
+		for _, n := range f.Nodes {
+			c.node(n)
+		}
+	}
+	return c.out, c.errs.Err()
+}
+
+type compiler struct {
+	vars        map[string]Type  // vars are the types of every variable whose type can be worked out.
+	nodes       map[string]*Node // nodes are all the nodes in the program, by title.
+	trackVisits bool             // trackVisits is set if any script asks which nodes have been visited.
+	lineIDs     map[string]Pos
+	out         *Output
+	errs        ErrorList
+}
+
+func (c *compiler) errorf(pos Pos, format string, args ...interface{}) {
+	c.errs = append(c.errs, &Error{pos, fmt.Sprintf(format, args...)})
+}
+
+// infer works out the types of variables from how they're declared, set, and compared.
+func (c *compiler) infer(files []*File) {
+	for pass := 0; pass < 3; pass++ { // types can depend on each other.
+		for _, f := range files {
+			for _, n := range f.Nodes {
+				Walk(n.Body, func(s Stmt) {
+					switch s := s.(type) {
+					case *Declare:
+						c.vars[s.Var] = typeOf(s.Value, c.vars)
+					case *Set:
+						if c.vars[s.Var] == TypeAny {
+							c.vars[s.Var] = typeOf(s.Value, c.vars)
+						}
+					}
+					for _, e := range stmtExprs(s) {
+						WalkExpr(e, c.inferExpr)
+					}
+				})
+			}
+		}
+	}
+}
+
+func (c *compiler) inferExpr(e Expr) {
+	switch e := e.(type) {
+	case *Binary:
+		x, xok := e.X.(*VarRef)
+		y, yok := e.Y.(*VarRef)
+		if xok && c.vars[x.Name] == TypeAny {
+			c.vars[x.Name] = typeOf(e.Y, c.vars)
+		}
+		if yok && c.vars[y.Name] == TypeAny {
+			c.vars[y.Name] = typeOf(e.X, c.vars)
+		}
+	case *Call:
+		if e.Func == "visited" || e.Func == "visited_count" {
+			c.trackVisits = true
+		}
+	}
+}
+
+// stmtExprs returns the expressions directly in the provided statement.
+func stmtExprs(s Stmt) []Expr {
+	switch s := s.(type) {
+	case *Line:
+		return append(s.Exprs[:len(s.Exprs):len(s.Exprs)], s.Cond)
+	case *OptionGroup:
+		var result []Expr
+		for _, o := range s.Options {
+			result = append(append(result, o.Exprs...), o.Cond)
+		}
+		return result
+	case *If:
+		var result []Expr
+		for _, cl := range s.Clauses {
+			result = append(result, cl.Cond)
+		}
+		return result
+	case *Set:
+		return []Expr{s.Value}
+	case *Declare:
+		return []Expr{s.Value}
+	case *Jump:
+		return []Expr{s.Expr}
+	case *Command:
+		return s.Exprs
+	}
+	return nil
+}
+
+// typeOf works out the type of an expression, without reporting any problems.
+func typeOf(e Expr, vars map[string]Type) Type {
+	switch e := e.(type) {
+	case *Literal:
+		switch e.Value.(type) {
+		case float32:
+			return TypeNumber
+		case string:
+			return TypeString
+		case bool:
+			return TypeBool
+		}
+	case *VarRef:
+		return vars[e.Name]
+	case *Call:
+		return Functions[e.Func].Returns
+	case *Unary:
+		if e.Op == "!" {
+			return TypeBool
+		}
+		return TypeNumber
+	case *Binary:
+		switch e.Op {
+		case "+":
+			if t := typeOf(e.X, vars); t != TypeAny {
+				return t
+			}
+			return typeOf(e.Y, vars)
+		case "-", "*", "/", "%":
+			return TypeNumber
+		}
+		return TypeBool
+	}
+	return TypeAny
+}
+
+// gen generates the instructions for a single node.
+type gen struct {
+	*compiler
+	title  string
+	node   *bytecode.Node
+	labels int
+}
+
+func (c *compiler) node(n *Node) {
+	g := &gen{
+		compiler: c,
+		title:    n.Title,
+		node:     &bytecode.Node{Name: n.Title, Labels: make(map[string]int32), Tags: n.Tags},
+	}
+	for _, h := range n.Headers {
+		g.node.Headers = append(g.node.Headers, &bytecode.Header{Key: h.Key, Value: h.Value})
+	}
+	g.stmts(n.Body)
+	g.exit()
+	g.emit(bytecode.Instruction_STOP)
+	c.out.Program.Nodes[n.Title] = g.node
+}
+
+func (g *gen) emit(op bytecode.Instruction_OpCode, operands ...*bytecode.Operand) {
+	g.node.Instructions = append(g.node.Instructions, &bytecode.Instruction{Opcode: op, Operands: operands})
+}
+
+func (g *gen) newLabel(name string) string {
+	defer func() { g.labels++ }()
+	return fmt.Sprintf("L%d%s", g.labels, name)
+}
+
+// mark points the provided label at the next instruction.
+func (g *gen) mark(label string) {
+	g.node.Labels[label] = int32(len(g.node.Instructions))
+}
+
+// exit counts a visit to the node, if anything wants to know; it's emitted wherever the node can be left.
+func (g *gen) exit() {
+	if !g.trackVisits {
+		return
+	}
+	v := "$Yarn.Internal.Visiting." + g.title
+	g.emit(bytecode.Instruction_PUSH_VARIABLE, str(v))
+	g.emit(bytecode.Instruction_PUSH_FLOAT, num(1))
+	g.emit(bytecode.Instruction_PUSH_FLOAT, num(2))
+	g.emit(bytecode.Instruction_CALL_FUNC, str("Number.Add"))
+	g.emit(bytecode.Instruction_STORE_VARIABLE, str(v))
+	g.emit(bytecode.Instruction_POP)
+}
+
+func (g *gen) stmts(stmts []Stmt) {
+	for i, s := range stmts {
+		switch s := s.(type) {
+		case *Line:
+			if i+1 < len(stmts) && !contains(s.Tags, TagLastLine) {
+				if _, ok := stmts[i+1].(*OptionGroup); ok {
+					s.Tags = append(s.Tags, TagLastLine)
+				}
+			}
+			g.addLine(s)
+			g.exprs(s.Pos, s.Exprs)
+			g.emit(bytecode.Instruction_RUN_LINE, str(s.ID), num(len(s.Exprs)))
+		case *OptionGroup:
+			g.options(s)
+		case *If:
+			end := g.newLabel("endif")
+			for _, cl := range s.Clauses {
+				if cl.Cond == nil { // else
+					g.stmts(cl.Body)
+					continue
+				}
+				skip := g.newLabel("skipclause")
+				g.cond(cl.Pos, cl.Cond)
+				g.emit(bytecode.Instruction_JUMP_IF_FALSE, str(skip))
+				g.emit(bytecode.Instruction_POP)
+				g.stmts(cl.Body)
+				g.emit(bytecode.Instruction_JUMP_TO, str(end))
+				g.mark(skip)
+				g.emit(bytecode.Instruction_POP)
+			}
+			g.mark(end)
+		case *Set:
+			t := g.expr(s.Pos, s.Value)
+			if want := g.vars[s.Var]; t != TypeAny && want != TypeAny && t != want {
+				g.errorf(s.Pos, "can't set %s to a %v; it's a %v", s.Var, t, want)
+			}
+			g.emit(bytecode.Instruction_STORE_VARIABLE, str(s.Var))
+			g.emit(bytecode.Instruction_POP)
+			if _, ok := g.out.Program.InitialValues[s.Var]; !ok {
+				if zero := zeroValue(g.vars[s.Var]); zero != nil {
+					g.out.Program.InitialValues[s.Var] = zero
+				}
+			}
+		case *Declare:
+			lit, ok := s.Value.(*Literal)
+			if !ok || lit.Value == nil {
+				g.errorf(s.Pos, "%s must be declared with a number, string, or bool", s.Var)
+				continue
+			}
+			g.out.Program.InitialValues[s.Var] = literal(lit)
+		case *Jump:
+			g.exit()
+			if s.Expr != nil {
+				g.expr(s.Pos, s.Expr)
+			} else {
+				if _, ok := g.nodes[s.Target]; !ok {
+					g.errorf(s.Pos, "jump to unknown node %s", s.Target)
+				}
+				g.emit(bytecode.Instruction_PUSH_STRING, str(s.Target))
+			}
+			g.emit(bytecode.Instruction_RUN_NODE)
+		case *Stop:
+			g.exit()
+			g.emit(bytecode.Instruction_STOP)
+		case *Command:
+			g.exprs(s.Pos, s.Exprs)
+			g.emit(bytecode.Instruction_RUN_COMMAND, str(s.Text), num(len(s.Exprs)))
+		}
+	}
+}
+
+func (g *gen) options(group *OptionGroup) {
+	end := g.newLabel("group_end")
+	var dests []string
+	for i, o := range group.Options {
+		dest := g.newLabel(fmt.Sprintf("shortcutoption_%s_%d", g.title, i+1))
+		dests = append(dests, dest)
+		if o.Cond != nil {
+			g.cond(o.Pos, o.Cond)
+		}
+		g.addLine(o.Line)
+		g.exprs(o.Pos, o.Exprs)
+		g.emit(bytecode.Instruction_ADD_OPTION, str(o.ID), str(dest), num(len(o.Exprs)), boolean(o.Cond != nil))
+	}
+	g.emit(bytecode.Instruction_SHOW_OPTIONS)
+	g.emit(bytecode.Instruction_JUMP)
+	for i, o := range group.Options {
+		g.mark(dests[i])
+		g.stmts(o.Body)
+		g.emit(bytecode.Instruction_JUMP_TO, str(end))
+	}
+	g.mark(end)
+	g.emit(bytecode.Instruction_POP) // the destination of the picked option.
+}
+
+func (g *gen) addLine(l *Line) {
+	if g.lineIDs == nil {
+		g.lineIDs = make(map[string]Pos)
+	}
+	if prev, ok := g.lineIDs[l.ID]; ok {
+		g.errorf(l.Pos, "line ID %s is already used at %v", l.ID, prev)
+		return
+	}
+	g.lineIDs[l.ID] = l.Pos
+	g.out.Lines = append(g.out.Lines, &TableLine{Line: l, Node: g.title})
+}
+
+func (g *gen) exprs(pos Pos, exprs []Expr) {
+	for _, e := range exprs {
+		g.expr(pos, e)
+	}
+}
+
+func (g *gen) cond(pos Pos, e Expr) {
+	if t := g.expr(pos, e); t != TypeBool && t != TypeAny {
+		g.errorf(pos, "condition is a %v, not a Bool", t)
+	}
+}
+
+// binaryOps are the names of the Yarn functions implementing each binary operator.
+var binaryOps = map[string]string{
+	"+": "Add", "-": "Minus", "*": "Multiply", "/": "Divide", "%": "Modulo",
+	"<": "LessThan", "<=": "LessThanOrEqualTo", ">": "GreaterThan", ">=": "GreaterThanOrEqualTo",
+	"==": "EqualTo", "!=": "NotEqualTo",
+	"&&": "And", "||": "Or", "^": "Xor",
+}
+
+// expr emits the instructions for an expression, and returns its type.
+func (g *gen) expr(pos Pos, e Expr) Type {
+	switch e := e.(type) {
+	case *Literal:
+		switch v := e.Value.(type) {
+		case float32:
+			g.emit(bytecode.Instruction_PUSH_FLOAT, num(v))
+		case string:
+			g.emit(bytecode.Instruction_PUSH_STRING, str(v))
+		case bool:
+			g.emit(bytecode.Instruction_PUSH_BOOL, boolean(v))
+		default:
+			g.emit(bytecode.Instruction_PUSH_NULL)
+		}
+	case *VarRef:
+		g.emit(bytecode.Instruction_PUSH_VARIABLE, str(e.Name))
+	case *Call:
+		f, ok := Functions[e.Func]
+		if !ok {
+			g.errorf(pos, "unknown function %s", e.Func)
+		} else if len(e.Args) != len(f.Params) {
+			g.errorf(pos, "%s takes %d arguments, not %d", e.Func, len(f.Params), len(e.Args))
+		}
+		for i, arg := range e.Args {
+			t := g.expr(pos, arg)
+			if i < len(f.Params) && f.Params[i] != TypeAny && t != TypeAny && t != f.Params[i] {
+				g.errorf(pos, "argument %d of %s is a %v, not a %v", i+1, e.Func, t, f.Params[i])
+			}
+		}
+		g.emit(bytecode.Instruction_PUSH_FLOAT, num(len(e.Args)))
+		g.emit(bytecode.Instruction_CALL_FUNC, str(e.Func))
+	case *Unary:
+		t := g.expr(pos, e.X)
+		fn, want := "Number.UnaryMinus", TypeNumber
+		if e.Op == "!" {
+			fn, want = "Bool.Not", TypeBool
+		}
+		if t != TypeAny && t != want {
+			g.errorf(pos, "can't use %s on a %v", e.Op, t)
+		}
+		g.emit(bytecode.Instruction_PUSH_FLOAT, num(1))
+		g.emit(bytecode.Instruction_CALL_FUNC, str(fn))
+	case *Binary:
+		tx, ty := g.expr(pos, e.X), g.expr(pos, e.Y)
+		if tx != TypeAny && ty != TypeAny && tx != ty {
+			g.errorf(pos, "can't use %s on a %v and a %v", e.Op, tx, ty)
+		}
+		t := tx
+		if t == TypeAny {
+			t = ty
+		}
+		switch e.Op {
+		case "-", "*", "/", "%", "<", "<=", ">", ">=":
+			if t != TypeAny && t != TypeNumber {
+				g.errorf(pos, "can't use %s on a %v", e.Op, t)
+			}
+			t = TypeNumber
+		case "+":
+			if t == TypeBool {
+				g.errorf(pos, "can't use + on a Bool")
+			}
+		case "&&", "||", "^":
+			if t != TypeAny && t != TypeBool {
+				g.errorf(pos, "can't use %s on a %v", e.Op, t)
+			}
+			t = TypeBool
+		}
+		fn := binaryOps[e.Op]
+		if t != TypeAny { // untyped operators are left to the VM to work out.
+			fn = t.String() + "." + fn
+		}
+		g.emit(bytecode.Instruction_PUSH_FLOAT, num(2))
+		g.emit(bytecode.Instruction_CALL_FUNC, str(fn))
+	}
+	return typeOf(e, g.vars)
+}
+
+func str(s string) *bytecode.Operand {
+	return &bytecode.Operand{Value: &bytecode.Operand_StringValue{StringValue: s}}
+}
+
+func num[T int | float32](f T) *bytecode.Operand {
+	return &bytecode.Operand{Value: &bytecode.Operand_FloatValue{FloatValue: float32(f)}}
+}
+
+func boolean(b bool) *bytecode.Operand {
+	return &bytecode.Operand{Value: &bytecode.Operand_BoolValue{BoolValue: b}}
+}
+
+func literal(l *Literal) *bytecode.Operand {
+	switch v := l.Value.(type) {
+	case float32:
+		return num(v)
+	case string:
+		return str(v)
+	case bool:
+		return boolean(v)
+	}
+	return nil
+}
+
+// zeroValue is the initial value of a variable of the provided type which is set, but never declared.
+func zeroValue(t Type) *bytecode.Operand {
+	switch t {
+	case TypeNumber:
+		return num(0)
+	case TypeString:
+		return str("")
+	case TypeBool:
+		return boolean(false)
+	}
+	return nil
+}
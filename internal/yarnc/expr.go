@@ -0,0 +1,303 @@
+package yarnc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Type is the type of a Yarn value.
+type Type uint8
+
+const (
+	TypeAny Type = iota // TypeAny is the type of values whose type can't be worked out; e.g. variables set by the game.
+	TypeNumber
+	TypeString
+	TypeBool
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeNumber:
+		return "Number"
+	case TypeString:
+		return "String"
+	case TypeBool:
+		return "Bool"
+	}
+	return "Any"
+}
+
+// Expr is an expression.
+type Expr interface {
+	expr()
+}
+
+// Literal is a number (float32), string, bool, or null (nil).
+type Literal struct {
+	Value interface{}
+}
+
+type VarRef struct {
+	Name string
+}
+
+type Call struct {
+	Func string
+	Args []Expr
+}
+
+type Unary struct {
+	Op string // Op is "-" or "!".
+	X  Expr
+}
+
+type Binary struct {
+	Op   string // Op is one of the operators in binaryPrecedence.
+	X, Y Expr
+}
+
+func (*Literal) expr() {}
+func (*VarRef) expr()  {}
+func (*Call) expr()    {}
+func (*Unary) expr()   {}
+func (*Binary) expr()  {}
+
+// WalkExpr calls visit for the provided expression and everything in it.
+func WalkExpr(e Expr, visit func(Expr)) {
+	if e == nil {
+		return
+	}
+	visit(e)
+	switch e := e.(type) {
+	case *Call:
+		for _, a := range e.Args {
+			WalkExpr(a, visit)
+		}
+	case *Unary:
+		WalkExpr(e.X, visit)
+	case *Binary:
+		WalkExpr(e.X, visit)
+		WalkExpr(e.Y, visit)
+	}
+}
+
+// binaryPrecedence lists binary operators, from loosest to tightest binding.
+var binaryPrecedence = [][]string{
+	{"||", "^"},
+	{"&&"},
+	{"==", "!="},
+	{"<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// keywordOps are the word forms of operators.
+var keywordOps = map[string]string{
+	"or": "||", "xor": "^", "and": "&&", "not": "!",
+	"is": "==", "eq": "==", "neq": "!=",
+	"lt": "<", "lte": "<=", "gt": ">", "gte": ">=",
+}
+
+type token struct {
+	kind string // kind is "num", "str", "var", "ident", or the operator itself.
+	text string
+}
+
+// lexExpr splits an expression into tokens.
+func lexExpr(s string) ([]token, error) {
+	var result []token
+	rs := []rune(s)
+	for i := 0; i < len(rs); {
+		r := rs[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(rs) && unicode.IsDigit(rs[i+1])):
+			j := i
+			for j < len(rs) && (unicode.IsDigit(rs[j]) || rs[j] == '.') {
+				j++
+			}
+			result = append(result, token{"num", string(rs[i:j])})
+			i = j
+		case r == '"':
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(rs) && rs[j] != '"'; j++ {
+				if rs[j] == '\\' && j+1 < len(rs) {
+					j++
+				}
+				sb.WriteRune(rs[j])
+			}
+			if j == len(rs) {
+				return nil, fmt.Errorf("unterminated string in %q", s)
+			}
+			result = append(result, token{"str", sb.String()})
+			i = j + 1
+		case r == '$' || r == '_' || unicode.IsLetter(r):
+			j := i + 1
+			for j < len(rs) && (rs[j] == '_' || rs[j] == '.' || unicode.IsLetter(rs[j]) || unicode.IsDigit(rs[j])) {
+				j++
+			}
+			word := string(rs[i:j])
+			switch {
+			case r == '$':
+				result = append(result, token{"var", word})
+			case keywordOps[word] != "":
+				result = append(result, token{keywordOps[word], word})
+			default:
+				result = append(result, token{"ident", word})
+			}
+			i = j
+		default:
+			op := ""
+			for _, candidate := range []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "+", "-", "*", "/", "%", "^", "(", ")", ",", "="} {
+				if strings.HasPrefix(string(rs[i:]), candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q in %q", r, s)
+			}
+			if op == "=" { // a lone = is comparison, outside of set and declare.
+				op = "=="
+			}
+			result = append(result, token{op, op})
+			i += len(op)
+		}
+	}
+	return result, nil
+}
+
+// ParseExpr parses a Yarn expression.
+func ParseExpr(s string) (Expr, error) {
+	toks, err := lexExpr(s)
+	if err != nil {
+		return nil, err
+	}
+	p := &exprParser{toks: toks, src: s}
+	e, err := p.binary(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.toks) {
+		return nil, fmt.Errorf("unexpected %q in %q", p.toks[p.pos].text, s)
+	}
+	return e, nil
+}
+
+type exprParser struct {
+	toks []token
+	pos  int
+	src  string
+}
+
+func (p *exprParser) peek() string {
+	if p.pos >= len(p.toks) {
+		return ""
+	}
+	return p.toks[p.pos].kind
+}
+
+func (p *exprParser) expect(kind string) error {
+	if p.peek() != kind {
+		return fmt.Errorf("expected %q in %q", kind, p.src)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *exprParser) binary(level int) (Expr, error) {
+	if level == len(binaryPrecedence) {
+		return p.unary()
+	}
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for contains(binaryPrecedence[level], p.peek()) {
+		op := p.toks[p.pos].kind
+		p.pos++
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &Binary{Op: op, X: x, Y: y}
+	}
+	return x, nil
+}
+
+func (p *exprParser) unary() (Expr, error) {
+	if op := p.peek(); op == "-" || op == "!" {
+		p.pos++
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &Unary{Op: op, X: x}, nil
+	}
+	return p.primary()
+}
+
+func (p *exprParser) primary() (Expr, error) {
+	if p.pos >= len(p.toks) {
+		return nil, fmt.Errorf("unexpected end of expression %q", p.src)
+	}
+	tok := p.toks[p.pos]
+	p.pos++
+	switch tok.kind {
+	case "num":
+		f, err := strconv.ParseFloat(tok.text, 32)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q", tok.text)
+		}
+		return &Literal{Value: float32(f)}, nil
+	case "str":
+		return &Literal{Value: tok.text}, nil
+	case "var":
+		return &VarRef{Name: tok.text}, nil
+	case "(":
+		e, err := p.binary(0)
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	case "ident":
+		switch tok.text {
+		case "true", "false":
+			return &Literal{Value: tok.text == "true"}, nil
+		case "null":
+			return &Literal{}, nil
+		}
+		call := &Call{Func: tok.text}
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		for p.peek() != ")" {
+			if len(call.Args) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			arg, err := p.binary(0)
+			if err != nil {
+				return nil, err
+			}
+			call.Args = append(call.Args, arg)
+		}
+		p.pos++
+		return call, nil
+	}
+	return nil, fmt.Errorf("unexpected %q in %q", tok.text, p.src)
+}
+
+func contains(ss []string, s string) bool {
+	for _, t := range ss {
+		if s == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,65 @@
+package yarnc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"google.golang.org/protobuf/proto"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// WriteFiles writes the program and its string tables to the provided directory, as yarn.LoadFiles expects to find
+// them: <name>.yarnc, <name>-Lines.csv and <name>-Metadata.csv. The same sources always produce the same files.
+func (o *Output) WriteFiles(dir string) error {
+	bytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(o.Program)
+	if err != nil {
+		return fmt.Errorf("marshaling program: %w", err)
+	}
+	base := filepath.Join(dir, o.Program.Name)
+	if err := os.WriteFile(base+".yarnc", bytes, 0644); err != nil {
+		return err
+	}
+	if err := writeFile(base+"-Lines.csv", o.WriteLines); err != nil {
+		return err
+	}
+	return writeFile(base+"-Metadata.csv", o.WriteMetadata)
+}
+
+func writeFile(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+	return f.Close()
+}
+
+// WriteLines writes the string table: the text of every line.
+func (o *Output) WriteLines(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "text", "file", "node", "lineNumber"})
+	for _, l := range o.Lines {
+		cw.Write([]string{l.ID, l.Text, l.File, l.Node, strconv.Itoa(l.Line.Line)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMetadata writes the metadata table: the tags of every line which has any.
+func (o *Output) WriteMetadata(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"id", "node", "lineNumber", "tags"})
+	for _, l := range o.Lines {
+		if len(l.Tags) == 0 {
+			continue
+		}
+		cw.Write(append([]string{l.ID, l.Node, strconv.Itoa(l.Line.Line)}, l.Tags...))
+	}
+	cw.Flush()
+	return cw.Error()
+}
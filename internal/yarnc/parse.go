@@ -0,0 +1,409 @@
+package yarnc
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Error is a problem at a particular place in a Yarn source file.
+type Error struct {
+	Pos
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: %s", e.Pos, e.Msg)
+}
+
+// ErrorList is every problem found in a set of Yarn sources.
+type ErrorList []*Error
+
+func (l ErrorList) Error() string {
+	var msgs []string
+	for _, e := range l {
+		msgs = append(msgs, e.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Err returns nil if the list is empty, or the list itself otherwise.
+func (l ErrorList) Err() error {
+	if len(l) == 0 {
+		return nil
+	}
+	return l
+}
+
+// srcLine is a single non-blank line from the body of a node.
+type srcLine struct {
+	Pos
+	indent int
+	text   string // text is the line, with surrounding whitespace trimmed.
+}
+
+// lineIDs hands out line IDs, which are unique and stable within a file.
+type lineIDs struct {
+	file string
+	next int
+}
+
+func (ids *lineIDs) id(node string) string {
+	defer func() { ids.next++ }()
+	return fmt.Sprintf("line:%s-%s-%d", ids.file, node, ids.next)
+}
+
+// Parse parses a Yarn source file. The name of the file is used in errors, and in line IDs.
+func Parse(name string, src string) (*File, error) {
+	f := &File{Name: name}
+	var errs ErrorList
+	ids := &lineIDs{file: name}
+
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); {
+		text := strings.TrimSpace(lines[i])
+		if text == "" || strings.HasPrefix(text, "//") {
+			i++
+			continue
+		}
+		node := &Node{Pos: Pos{File: name, Line: i + 1}}
+		for ; i < len(lines) && strings.TrimSpace(lines[i]) != "---"; i++ { // headers
+			text := strings.TrimSpace(lines[i])
+			if text == "" || strings.HasPrefix(text, "//") {
+				continue
+			}
+			key, value, ok := strings.Cut(text, ":")
+			if !ok {
+				errs = append(errs, &Error{Pos{name, i + 1}, fmt.Sprintf("expected a header or ---, found %q", text)})
+				continue
+			}
+			h := Header{Key: strings.TrimSpace(key), Value: strings.TrimSpace(value)}
+			node.Headers = append(node.Headers, h)
+			switch h.Key {
+			case "title":
+				node.Title = h.Value
+			case "tags":
+				node.Tags = strings.Fields(h.Value)
+			}
+		}
+		if node.Title == "" {
+			errs = append(errs, &Error{node.Pos, "node has no title"})
+		}
+		i++ // skip ---
+		var body []srcLine
+		for ; i < len(lines) && strings.TrimSpace(lines[i]) != "==="; i++ {
+			text := strings.TrimSpace(lines[i])
+			if text == "" || strings.HasPrefix(text, "//") {
+				continue
+			}
+			indent := len(lines[i]) - len(strings.TrimLeftFunc(lines[i], unicode.IsSpace))
+			body = append(body, srcLine{Pos: Pos{name, i + 1}, indent: indent, text: text})
+		}
+		if i == len(lines) {
+			errs = append(errs, &Error{node.Pos, fmt.Sprintf("node %s is missing ===", node.Title)})
+		}
+		i++ // skip ===
+
+		p := &parser{node: node.Title, lines: body, ids: ids}
+		node.Body = p.statements(nil)
+		errs = append(errs, p.errs...)
+		f.Nodes = append(f.Nodes, node)
+	}
+	return f, errs.Err()
+}
+
+type parser struct {
+	node  string
+	lines []srcLine
+	pos   int
+	ids   *lineIDs
+	errs  ErrorList
+}
+
+func (p *parser) errorf(pos Pos, format string, args ...interface{}) {
+	p.errs = append(p.errs, &Error{pos, fmt.Sprintf(format, args...)})
+}
+
+// statements parses statements until the end of the lines, or until a command with one of the provided keywords.
+func (p *parser) statements(stopAt []string) []Stmt {
+	var result []Stmt
+	for p.pos < len(p.lines) {
+		l := p.lines[p.pos]
+		if strings.HasPrefix(l.text, "->") {
+			result = append(result, p.optionGroup())
+			continue
+		}
+		if !strings.HasPrefix(l.text, "<<") {
+			p.pos++
+			result = append(result, p.line(l, false))
+			continue
+		}
+		if keyword, _ := commandKeyword(commandText(l.text)); contains(stopAt, keyword) {
+			return result
+		}
+		cmd := p.command(l)
+		keyword, rest := commandKeyword(cmd)
+		p.pos++
+		switch keyword {
+		case "if":
+			result = append(result, p.ifStmt(l, rest))
+		case "elseif", "else", "endif":
+			p.errorf(l.Pos, "%s without if", keyword)
+		case "set", "declare":
+			v, val, ok := cutAssignment(rest)
+			if !ok {
+				p.errorf(l.Pos, "expected `%s $variable to value`", keyword)
+				continue
+			}
+			if keyword == "set" {
+				result = append(result, &Set{Pos: l.Pos, Var: v, Value: p.expr(l.Pos, val)})
+			} else {
+				result = append(result, &Declare{Pos: l.Pos, Var: v, Value: p.expr(l.Pos, val)})
+			}
+		case "jump":
+			j := &Jump{Pos: l.Pos, Target: rest}
+			if strings.HasPrefix(rest, "{") && strings.HasSuffix(rest, "}") {
+				j.Target, j.Expr = "", p.expr(l.Pos, rest[1:len(rest)-1])
+			} else if rest == "" || strings.IndexFunc(rest, unicode.IsSpace) >= 0 {
+				p.errorf(l.Pos, "expected `jump NodeName`")
+			}
+			result = append(result, j)
+		case "stop":
+			result = append(result, &Stop{Pos: l.Pos})
+		default:
+			text, exprs := p.interpolate(l.Pos, cmd)
+			result = append(result, &Command{Pos: l.Pos, Text: text, Exprs: exprs})
+		}
+	}
+	if len(stopAt) > 0 {
+		p.errorf(p.lastPos(), "expected << %s >>", stopAt[len(stopAt)-1])
+	}
+	return result
+}
+
+func (p *parser) lastPos() Pos {
+	if len(p.lines) == 0 {
+		return Pos{}
+	}
+	return p.lines[len(p.lines)-1].Pos
+}
+
+func (p *parser) ifStmt(l srcLine, cond string) *If {
+	result := &If{Pos: l.Pos}
+	clause := &Clause{Pos: l.Pos, Cond: p.expr(l.Pos, cond)}
+	sawElse := false
+	for {
+		clause.Body = p.statements([]string{"elseif", "else", "endif"})
+		result.Clauses = append(result.Clauses, clause)
+		if p.pos >= len(p.lines) {
+			return result // missing endif; already reported.
+		}
+		next := p.lines[p.pos]
+		keyword, rest := commandKeyword(p.command(next))
+		p.pos++
+		if keyword == "endif" {
+			return result
+		}
+		if sawElse {
+			p.errorf(next.Pos, "%s after else", keyword)
+		}
+		if keyword == "else" {
+			sawElse = true
+			clause = &Clause{Pos: next.Pos}
+		} else {
+			clause = &Clause{Pos: next.Pos, Cond: p.expr(next.Pos, rest)}
+		}
+	}
+}
+
+// optionGroup parses consecutive options at the same indentation, along with their bodies, which are indented further.
+func (p *parser) optionGroup() *OptionGroup {
+	first := p.lines[p.pos]
+	group := &OptionGroup{Pos: first.Pos}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == first.indent && strings.HasPrefix(p.lines[p.pos].text, "->") {
+		l := p.lines[p.pos]
+		l.text = strings.TrimSpace(strings.TrimPrefix(l.text, "->"))
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.lines) && p.lines[p.pos].indent > first.indent {
+			p.pos++
+		}
+		body := &parser{node: p.node, lines: p.lines[start:p.pos], ids: p.ids}
+		opt := &Option{Line: p.line(l, true)}
+		opt.Body = body.statements(nil)
+		p.errs = append(p.errs, body.errs...)
+		group.Options = append(group.Options, opt)
+	}
+	return group
+}
+
+// line parses a line of dialogue; options may have a condition.
+func (p *parser) line(l srcLine, option bool) *Line {
+	result := &Line{Pos: l.Pos}
+	text := l.text
+	if i := commentStart(text); i >= 0 {
+		text = text[:i]
+	}
+	if i := indexUnescaped(text, '#'); i >= 0 {
+		result.Tags = strings.Fields(text[i+1:])
+		for j := range result.Tags {
+			result.Tags[j] = strings.TrimPrefix(result.Tags[j], "#")
+		}
+		text = text[:i]
+	}
+	if i := strings.Index(text, "<<"); i >= 0 {
+		cond := strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text[i+2:]), ">>"))
+		text = text[:i]
+		keyword, rest := commandKeyword(cond)
+		switch {
+		case !option:
+			p.errorf(l.Pos, "only options can have conditions")
+		case keyword != "if":
+			p.errorf(l.Pos, "expected `<< if condition >>` after option")
+		default:
+			result.Cond = p.expr(l.Pos, rest)
+		}
+	}
+	result.Text, result.Exprs = p.interpolate(l.Pos, strings.TrimSpace(text))
+
+	var tags []string
+	for _, t := range result.Tags {
+		if strings.HasPrefix(t, "line:") {
+			result.ID = t
+		} else {
+			tags = append(tags, t)
+		}
+	}
+	result.Tags = tags
+	if result.ID == "" {
+		result.ID = p.ids.id(p.node)
+	}
+	return result
+}
+
+// command returns the text between the << and >> on the provided line, reporting anything malformed.
+func (p *parser) command(l srcLine) string {
+	end := strings.Index(l.text, ">>")
+	if end < 0 {
+		p.errorf(l.Pos, "missing >>")
+	} else if after := strings.TrimSpace(l.text[end+2:]); after != "" && !strings.HasPrefix(after, "//") && !strings.HasPrefix(after, "#") {
+		p.errorf(l.Pos, "unexpected %q after >>", after)
+	}
+	return commandText(l.text)
+}
+
+// commandText returns the text between the << and >> on the provided line.
+func commandText(line string) string {
+	line = strings.TrimPrefix(line, "<<")
+	if end := strings.Index(line, ">>"); end >= 0 {
+		line = line[:end]
+	}
+	return strings.TrimSpace(line)
+}
+
+// markupShorthand matches the start of a markup tag written like [color=red], which Yarn reads as [color color="red"].
+// The string table only understands the longer form.
+var markupShorthand = regexp.MustCompile(`^\[(\w+)\s*=\s*([^\s"{\]/]*)`)
+
+// interpolate replaces each {expression} in text with {0}, {1}, etc., unescapes escaped characters, and expands markup
+// shorthand.
+func (p *parser) interpolate(pos Pos, text string) (string, []Expr) {
+	var sb strings.Builder
+	var exprs []Expr
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '\\' && i+1 < len(text):
+			i++
+			if text[i] == '[' || text[i] == ']' { // markup is escaped later, by the string table.
+				sb.WriteByte('\\')
+			}
+			sb.WriteByte(text[i])
+		case c == '{':
+			end := strings.IndexByte(text[i:], '}')
+			if end < 0 {
+				p.errorf(pos, "missing }")
+				return sb.String(), exprs
+			}
+			exprs = append(exprs, p.expr(pos, text[i+1:i+end]))
+			fmt.Fprintf(&sb, "{%d}", len(exprs)-1)
+			i += end
+		case c == '[' && markupShorthand.MatchString(text[i:]):
+			m := markupShorthand.FindStringSubmatch(text[i:])
+			fmt.Fprintf(&sb, "[%s %s=", m[1], m[1])
+			if m[2] != "" { // quoted values and {expressions} are left for the string table.
+				fmt.Fprintf(&sb, "%q", m[2])
+			}
+			i += len(m[0]) - 1
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), exprs
+}
+
+func (p *parser) expr(pos Pos, s string) Expr {
+	e, err := ParseExpr(s)
+	if err != nil {
+		p.errorf(pos, "%v", err)
+		return nil
+	}
+	return e
+}
+
+// commandKeyword splits the first word off a command.
+func commandKeyword(cmd string) (keyword, rest string) {
+	keyword, rest, _ = strings.Cut(strings.TrimSpace(cmd), " ")
+	return keyword, strings.TrimSpace(rest)
+}
+
+// cutAssignment splits `$variable to value` or `$variable = value`.
+func cutAssignment(s string) (variable, value string, ok bool) {
+	i := strings.IndexAny(s, " =")
+	if i < 0 || !strings.HasPrefix(s, "$") {
+		return "", "", false
+	}
+	variable, value = s[:i], strings.TrimSpace(s[i:])
+	switch {
+	case strings.HasPrefix(value, "to "):
+		return variable, value[3:], true
+	case strings.HasPrefix(value, "="):
+		return variable, value[1:], true
+	}
+	return "", "", false
+}
+
+// commentStart returns the index of a // comment in the provided line, or -1. Only a // at the start of the line or
+// after whitespace starts a comment, so URLs are left alone.
+func commentStart(s string) int {
+	for i := 0; i+1 < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+			continue
+		}
+		if s[i] == '/' && s[i+1] == '/' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t') {
+			return i
+		}
+	}
+	return -1
+}
+
+// indexUnescaped returns the index of the first unescaped c outside of braces, or -1.
+func indexUnescaped(s string, c byte) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case c:
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
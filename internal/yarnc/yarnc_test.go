@@ -0,0 +1,147 @@
+package yarnc
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/DrJosh9000/yarn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+const testSource = `title: Start
+portrait: teller
+---
+<< set $count to 1 >>
+Hello, {$name}! #happy
+<< if $count > 1 >>
+    Never shown.
+<< elseif $count == 1 >>
+    Counted once.
+<< endif >>
+Pick one.
+-> Go on
+    << jump Next >>
+-> Stay <<if visited("Next")>>
+    Stayed.
+<< shake {$count + 1} >> // a comment
+===
+title: Next
+---
+Bye.
+===
+`
+
+// recorder is a yarn.DialogueHandler which renders everything it's handed.
+type recorder struct {
+	yarn.FakeDialogueHandler
+	st  *yarn.StringTable
+	out []string
+}
+
+func (r *recorder) Line(line yarn.Line) error {
+	s, err := r.st.Render(line)
+	if err != nil {
+		return err
+	}
+	r.out = append(r.out, s.String())
+	return nil
+}
+
+func (r *recorder) Options(opts []yarn.Option) (int, error) {
+	for _, o := range opts {
+		r.out = append(r.out, fmt.Sprintf("-> %s %v", r.st.Table[o.Line.ID].Text, o.IsAvailable))
+	}
+	return opts[0].ID, nil
+}
+
+func (r *recorder) Command(cmd string) error {
+	r.out = append(r.out, "<<"+cmd+">>")
+	return nil
+}
+
+func compileTest(t *testing.T, src string) *Output {
+	f, err := Parse("Test.yarn", src)
+	require.NoError(t, err)
+	out, err := Compile("test", []*File{f})
+	require.NoError(t, err)
+	return out
+}
+
+func TestCompile_Runs(t *testing.T) {
+	out := compileTest(t, testSource)
+
+	var lines, meta bytes.Buffer
+	require.NoError(t, out.WriteLines(&lines))
+	require.NoError(t, out.WriteMetadata(&meta))
+	st, err := yarn.ReadStringTable(&lines, "en-US")
+	require.NoError(t, err)
+
+	rec := &recorder{st: st}
+	vm := &yarn.VirtualMachine{
+		Program: out.Program,
+		Handler: rec,
+		Vars:    yarn.MapVariableStorage{"$name": "Sam"},
+	}
+	require.NoError(t, vm.Run("Start"))
+
+	assert.EqualValues(t, []string{
+		"Hello, Sam!", "Counted once.", "Pick one.", "-> Go on true", "-> Stay false", "Bye.",
+	}, rec.out)
+	assert.EqualValues(t, "teller", out.Program.Nodes["Start"].Headers[1].Value)
+	assert.Contains(t, meta.String(), "line:Test.yarn-Start-0,Start,5,happy\n")
+	assert.Contains(t, meta.String(), "line:Test.yarn-Start-3,Start,11,lastline\n")
+}
+
+func TestCompile_Command(t *testing.T) {
+	out := compileTest(t, "title: Start\n---\n<< set $n to 2 >>\n<< shake {$n + 1} fast >> // comment\n===\n")
+
+	rec := &recorder{}
+	vm := &yarn.VirtualMachine{Program: out.Program, Handler: rec, Vars: yarn.MapVariableStorage{}}
+	require.NoError(t, vm.Run("Start"))
+
+	assert.EqualValues(t, []string{"<<shake 3 fast>>"}, rec.out)
+}
+
+func TestCompile_Markup(t *testing.T) {
+	out := compileTest(t, "title: Start\n---\n<< set $ms to 250 >>\n"+
+		"[b]Hey![/b] [color=red]{$ms}[/color][pause={$ms}/] \\[not markup\\] [wave = 2]ok[/wave]\n===\n")
+
+	assert.EqualValues(t, `[b]Hey![/b] [color color="red"]{0}[/color][pause pause={1}/] \[not markup\] [wave wave="2"]ok[/wave]`,
+		out.Lines[0].Text)
+	var lines bytes.Buffer
+	require.NoError(t, out.WriteLines(&lines))
+	_, err := yarn.ReadStringTable(&lines, "en-US")
+	assert.NoError(t, err)
+}
+
+func TestCompile_Errors(t *testing.T) {
+	tests := []struct {
+		name, src string
+	}{
+		{"missing node", "title: Start\n---\n<< jump Nowhere >>\n===\n"},
+		{"type mismatch", "title: Start\n---\n<< set $n to 1 >>\n<< if $n == \"one\" >>\nHi.\n<< endif >>\n===\n"},
+		{"unclosed if", "title: Start\n---\n<< if true >>\nHi.\n===\n"},
+		{"unknown function", "title: Start\n---\n<< if nope() >>\nHi.\n<< endif >>\n===\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			f, err := Parse("Test.yarn", test.src)
+			if err == nil {
+				_, err = Compile("test", []*File{f})
+			}
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseExpr(t *testing.T) {
+	e, err := ParseExpr(`$a + 2 * 3 > 4 and not $b`)
+	require.NoError(t, err)
+
+	and := e.(*Binary)
+	assert.EqualValues(t, "&&", and.Op)
+	assert.EqualValues(t, ">", and.X.(*Binary).Op)
+	assert.EqualValues(t, "+", and.X.(*Binary).X.(*Binary).Op)
+	assert.EqualValues(t, "!", and.Y.(*Unary).Op)
+}
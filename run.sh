@@ -4,8 +4,8 @@ set -e
 
 echo "== Recompiling latest yarn =="
 
-ysc compile -n game -o internal/gamedata/yarn/bin \
-  internal/gamedata/yarn/**.yarn
+go run ./cmd/yarnc -n game -o internal/gamedata/yarn/bin internal/gamedata/yarn
+go run ./cmd/yarnlint
 
 printf "== Running game ==\n\n"
 